@@ -0,0 +1,203 @@
+/**FileHeader
+ * @Author: Liangkang Zhang
+ * @Date: 2026/2/20 20:31:02
+ * @LastEditors: Liangkang Zhang
+ * @LastEditTime: 2026/2/20 20:31:02
+ * @Description:
+ * @Copyright: Copyright (©)}) 2026 Liangkang Zhang<lkzhang98@gmail.com>. All rights reserved. Use of this source code is governed by a MIT style license that can be found in the LICENSE file.. All rights reserved.
+ * @Email: lkzhang98@gmail.com
+ * @Repository: https://github.com/geminik12/autostack
+ */
+package apikey
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/geminik12/autostack/log"
+	"github.com/geminik12/autostack/model"
+)
+
+// API Key 相关的预定义错误
+var (
+	ErrInvalidKey  = errors.New("invalid api key")
+	ErrKeyExpired  = errors.New("api key has expired")
+	ErrKeyNotFound = errors.New("api key not found")
+)
+
+const (
+	// prefixBytes 是 API Key 前缀的随机字节数，前缀以十六进制明文保存，用于查找.
+	prefixBytes = 6
+	// secretBytes 是 API Key 密钥部分的随机字节数.
+	secretBytes = 32
+	// defaultLastUsedInterval 是两次更新最后使用时间的最小间隔.
+	defaultLastUsedInterval = time.Minute
+)
+
+// Option 用于配置 Manager.
+type Option func(*Manager)
+
+// WithLastUsedInterval 设置两次更新最后使用时间的最小间隔，默认为 1 分钟.
+// 频繁使用的 API Key 不会在每次请求时都写入存储，为 0 时每次验证都更新.
+func WithLastUsedInterval(interval time.Duration) Option {
+	return func(m *Manager) {
+		m.lastUsedInterval = interval
+	}
+}
+
+// IssueOption 用于配置签发的 API Key.
+type IssueOption func(*model.APIKeyM)
+
+// WithScopes 设置 API Key 的授权范围.
+func WithScopes(scopes ...string) IssueOption {
+	return func(k *model.APIKeyM) {
+		k.Scopes = strings.Join(scopes, " ")
+	}
+}
+
+// WithTTL 设置 API Key 的有效期，默认永不过期.
+func WithTTL(ttl time.Duration) IssueOption {
+	return func(k *model.APIKeyM) {
+		expiresAt := time.Now().Add(ttl)
+		k.ExpiresAt = &expiresAt
+	}
+}
+
+// Manager 负责 API Key 的签发、验证和吊销.
+// API Key 的格式为 "<prefix>.<secret>"，存储中只保存明文前缀和整个 Key 的 SHA-256 哈希值，
+// 验证时根据前缀查找记录，再比较哈希值.
+type Manager struct {
+	store            Store
+	lastUsedInterval time.Duration
+}
+
+// NewManager 创建一个使用 store 保存 API Key 的 Manager.
+func NewManager(store Store, opts ...Option) *Manager {
+	m := &Manager{
+		store:            store,
+		lastUsedInterval: defaultLastUsedInterval,
+	}
+
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	return m
+}
+
+// Issue 为用户 userID 签发一个名为 name 的 API Key，返回 API Key 明文及其存储记录.
+// 明文只在签发时返回一次，之后无法再从存储中恢复.
+func (m *Manager) Issue(ctx context.Context, userID, name string, opts ...IssueOption) (string, *model.APIKeyM, error) {
+	prefix, err := randomString(prefixBytes, hex.EncodeToString)
+	if err != nil {
+		return "", nil, err
+	}
+	secret, err := randomString(secretBytes, base64.RawURLEncoding.EncodeToString)
+	if err != nil {
+		return "", nil, err
+	}
+	key := prefix + "." + secret
+
+	record := &model.APIKeyM{
+		Prefix:    prefix,
+		Hash:      hashKey(key),
+		UserID:    userID,
+		Name:      name,
+		CreatedAt: time.Now(),
+	}
+	for _, opt := range opts {
+		opt(record)
+	}
+
+	if err := m.store.Create(ctx, record); err != nil {
+		return "", nil, err
+	}
+
+	return key, record, nil
+}
+
+// Verify 验证 API Key 明文，返回对应的存储记录，并更新最后使用时间.
+// Key 格式错误、不存在或哈希值不匹配时返回 ErrInvalidKey，已过期时返回 ErrKeyExpired.
+func (m *Manager) Verify(ctx context.Context, key string) (*model.APIKeyM, error) {
+	prefix, _, ok := strings.Cut(key, ".")
+	if !ok || prefix == "" {
+		return nil, ErrInvalidKey
+	}
+
+	record, err := m.store.GetByPrefix(ctx, prefix)
+	if err != nil {
+		if errors.Is(err, ErrKeyNotFound) {
+			return nil, ErrInvalidKey
+		}
+		return nil, err
+	}
+
+	if subtle.ConstantTimeCompare([]byte(hashKey(key)), []byte(record.Hash)) != 1 {
+		return nil, ErrInvalidKey
+	}
+
+	now := time.Now()
+	if record.ExpiresAt != nil && now.After(*record.ExpiresAt) {
+		return nil, ErrKeyExpired
+	}
+
+	if record.LastUsedAt == nil || now.Sub(*record.LastUsedAt) >= m.lastUsedInterval {
+		// 最后使用时间只用于审计，更新失败不影响认证结果
+		if err := m.store.UpdateLastUsed(ctx, prefix, now); err != nil {
+			log.Errorw(err, "Failed to update api key last used time", "prefix", prefix)
+		} else {
+			record.LastUsedAt = &now
+		}
+	}
+
+	return record, nil
+}
+
+// Revoke 吊销前缀为 prefix 的 API Key.
+func (m *Manager) Revoke(ctx context.Context, prefix string) error {
+	return m.store.Delete(ctx, prefix)
+}
+
+// List 返回用户 userID 的全部 API Key.
+func (m *Manager) List(ctx context.Context, userID string) ([]*model.APIKeyM, error) {
+	return m.store.ListByUser(ctx, userID)
+}
+
+// Scopes 返回 API Key 的授权范围.
+func Scopes(record *model.APIKeyM) []string {
+	return strings.Fields(record.Scopes)
+}
+
+// HasScopes 判断 API Key 是否拥有全部的 scopes.
+func HasScopes(record *model.APIKeyM, scopes ...string) bool {
+	granted := Scopes(record)
+	for _, scope := range scopes {
+		if !slices.Contains(granted, scope) {
+			return false
+		}
+	}
+	return true
+}
+
+// hashKey 返回 API Key 的 SHA-256 哈希值. API Key 由足够长的随机数生成，无需使用慢哈希.
+func hashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// randomString 生成 n 个随机字节并使用 encode 编码.
+func randomString(n int, encode func([]byte) string) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return encode(b), nil
+}
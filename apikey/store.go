@@ -0,0 +1,174 @@
+/**FileHeader
+ * @Author: Liangkang Zhang
+ * @Date: 2026/2/20 20:52:47
+ * @LastEditors: Liangkang Zhang
+ * @LastEditTime: 2026/2/20 20:52:47
+ * @Description:
+ * @Copyright: Copyright (©)}) 2026 Liangkang Zhang<lkzhang98@gmail.com>. All rights reserved. Use of this source code is governed by a MIT style license that can be found in the LICENSE file.. All rights reserved.
+ * @Email: lkzhang98@gmail.com
+ * @Repository: https://github.com/geminik12/autostack
+ */
+package apikey
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/geminik12/autostack/model"
+	"gorm.io/gorm"
+)
+
+// Store 定义了 API Key 的存储接口.
+type Store interface {
+	// Create 保存一个新的 API Key.
+	Create(ctx context.Context, key *model.APIKeyM) error
+	// GetByPrefix 根据前缀查找 API Key，不存在时返回 ErrKeyNotFound.
+	GetByPrefix(ctx context.Context, prefix string) (*model.APIKeyM, error)
+	// UpdateLastUsed 更新 API Key 的最后使用时间.
+	UpdateLastUsed(ctx context.Context, prefix string, usedAt time.Time) error
+	// Delete 删除 API Key，不存在时返回 ErrKeyNotFound.
+	Delete(ctx context.Context, prefix string) error
+	// ListByUser 返回用户的全部 API Key，按创建时间排序.
+	ListByUser(ctx context.Context, userID string) ([]*model.APIKeyM, error)
+}
+
+// MemoryStore 是基于内存的 API Key 存储，适用于单实例部署和测试.
+type MemoryStore struct {
+	mu   sync.RWMutex
+	keys map[string]model.APIKeyM
+}
+
+var _ Store = (*MemoryStore)(nil)
+
+// NewMemoryStore 创建一个基于内存的 API Key 存储.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{keys: make(map[string]model.APIKeyM)}
+}
+
+// Create 保存一个新的 API Key.
+func (s *MemoryStore) Create(ctx context.Context, key *model.APIKeyM) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.keys[key.Prefix]; ok {
+		return errors.New("api key prefix already exists")
+	}
+	s.keys[key.Prefix] = *key
+	return nil
+}
+
+// GetByPrefix 根据前缀查找 API Key.
+func (s *MemoryStore) GetByPrefix(ctx context.Context, prefix string) (*model.APIKeyM, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	key, ok := s.keys[prefix]
+	if !ok {
+		return nil, ErrKeyNotFound
+	}
+	return &key, nil
+}
+
+// UpdateLastUsed 更新 API Key 的最后使用时间.
+func (s *MemoryStore) UpdateLastUsed(ctx context.Context, prefix string, usedAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key, ok := s.keys[prefix]
+	if !ok {
+		return ErrKeyNotFound
+	}
+	key.LastUsedAt = &usedAt
+	s.keys[prefix] = key
+	return nil
+}
+
+// Delete 删除 API Key.
+func (s *MemoryStore) Delete(ctx context.Context, prefix string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.keys[prefix]; !ok {
+		return ErrKeyNotFound
+	}
+	delete(s.keys, prefix)
+	return nil
+}
+
+// ListByUser 返回用户的全部 API Key.
+func (s *MemoryStore) ListByUser(ctx context.Context, userID string) ([]*model.APIKeyM, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var keys []*model.APIKeyM
+	for _, key := range s.keys {
+		if key.UserID == userID {
+			keys = append(keys, &key)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].CreatedAt.Before(keys[j].CreatedAt) })
+
+	return keys, nil
+}
+
+// GormStore 是基于 gorm 的 API Key 存储，API Key 保存在 api_key 表.
+type GormStore struct {
+	db *gorm.DB
+}
+
+var _ Store = (*GormStore)(nil)
+
+// NewGormStore 创建一个基于 gorm 的 API Key 存储，api_key 表不存在时会自动创建.
+func NewGormStore(db *gorm.DB) (*GormStore, error) {
+	if err := db.AutoMigrate(&model.APIKeyM{}); err != nil {
+		return nil, err
+	}
+
+	return &GormStore{db: db}, nil
+}
+
+// Create 保存一个新的 API Key.
+func (s *GormStore) Create(ctx context.Context, key *model.APIKeyM) error {
+	return s.db.WithContext(ctx).Create(key).Error
+}
+
+// GetByPrefix 根据前缀查找 API Key.
+func (s *GormStore) GetByPrefix(ctx context.Context, prefix string) (*model.APIKeyM, error) {
+	var key model.APIKeyM
+	if err := s.db.WithContext(ctx).Where("prefix = ?", prefix).First(&key).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrKeyNotFound
+		}
+		return nil, err
+	}
+	return &key, nil
+}
+
+// UpdateLastUsed 更新 API Key 的最后使用时间.
+func (s *GormStore) UpdateLastUsed(ctx context.Context, prefix string, usedAt time.Time) error {
+	return s.db.WithContext(ctx).Model(&model.APIKeyM{}).Where("prefix = ?", prefix).Update("lastUsedAt", usedAt).Error
+}
+
+// Delete 删除 API Key.
+func (s *GormStore) Delete(ctx context.Context, prefix string) error {
+	result := s.db.WithContext(ctx).Where("prefix = ?", prefix).Delete(&model.APIKeyM{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrKeyNotFound
+	}
+	return nil
+}
+
+// ListByUser 返回用户的全部 API Key.
+func (s *GormStore) ListByUser(ctx context.Context, userID string) ([]*model.APIKeyM, error) {
+	var keys []*model.APIKeyM
+	if err := s.db.WithContext(ctx).Where("userID = ?", userID).Order("createdAt").Find(&keys).Error; err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
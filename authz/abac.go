@@ -0,0 +1,182 @@
+/**FileHeader
+ * @Author: Liangkang Zhang
+ * @Date: 2026/2/19 20:27:53
+ * @LastEditors: Liangkang Zhang
+ * @LastEditTime: 2026/2/19 20:27:53
+ * @Description:
+ * @Copyright: Copyright (©)}) 2026 Liangkang Zhang<lkzhang98@gmail.com>. All rights reserved. Use of this source code is governed by a MIT style license that can be found in the LICENSE file.. All rights reserved.
+ * @Email: lkzhang98@gmail.com
+ * @Repository: https://github.com/geminik12/autostack
+ */
+package authz
+
+import (
+	"context"
+	"sync"
+
+	"github.com/casbin/casbin/v2/util"
+	"github.com/geminik12/autostack/contextx"
+)
+
+// Attributes 是 ABAC 规则判断时可以使用的请求属性.
+type Attributes struct {
+	Subject string
+	Object  string
+	Action  string
+	// Params 是请求参数，HTTP 请求为路由参数（例如 :userID），gRPC 请求为请求消息中的字段.
+	Params map[string]string
+	// Resource 是 ResourceLoader 加载的目标资源，规则没有配置 ResourceLoader 时为 nil.
+	Resource any
+}
+
+// Param 返回名为 name 的请求参数.
+func (attrs *Attributes) Param(name string) string {
+	return attrs.Params[name]
+}
+
+// ResourceLoader 根据请求属性加载目标资源，例如根据 :postID 从数据库中查询文章.
+type ResourceLoader func(ctx context.Context, attrs *Attributes) (any, error)
+
+// RuleFunc 是一条 ABAC 规则，返回 false 时拒绝访问.
+type RuleFunc func(ctx context.Context, attrs *Attributes) (bool, error)
+
+// RuleOption 用于配置 ABAC 规则.
+type RuleOption func(*abacRule)
+
+// WithResourceLoader 设置规则的资源加载函数，加载的资源通过 Attributes.Resource 传给规则.
+func WithResourceLoader(loader ResourceLoader) RuleOption {
+	return func(r *abacRule) {
+		r.loader = loader
+	}
+}
+
+// abacRule 是一条注册的 ABAC 规则.
+type abacRule struct {
+	name   string
+	object string
+	action string
+	loader ResourceLoader
+	check  RuleFunc
+}
+
+// matches 判断规则是否适用于 object 和 action.
+func (r *abacRule) matches(object, action string) bool {
+	return (r.action == "*" || r.action == action) && util.KeyMatch2(object, r.object)
+}
+
+// ABACAuthorizer 在 Casbin 路径授权的基础上执行基于属性的访问控制（ABAC）规则，
+// 用于表达 "用户只能修改自己的资料" 这类依赖请求参数或资源归属的规则.
+// 只有 Casbin 允许访问且所有适用的规则都通过时才允许访问.
+// ABACAuthorizer 实现了中间件的 Authorizer 接口，可以同时用于 Gin 中间件和 gRPC 拦截器，
+// 请求参数由中间件通过 contextx.WithParams 传入.
+type ABACAuthorizer struct {
+	authz *Authz
+
+	mu    sync.RWMutex
+	rules []*abacRule
+}
+
+// NewABACAuthorizer 创建一个使用 a 进行路径授权的 ABACAuthorizer.
+func NewABACAuthorizer(a *Authz) *ABACAuthorizer {
+	return &ABACAuthorizer{authz: a}
+}
+
+// AddRule 注册一条名为 name 的规则，规则适用于匹配 object 的请求对象和 action 动作.
+// object 支持 KeyMatch2 语法，例如 "/v1/users/:userID" 或 "/v1/posts/*"，同时可以匹配请求路径和路由模板；
+// action 为 "*" 时适用于所有动作.
+func (z *ABACAuthorizer) AddRule(name, object, action string, check RuleFunc, opts ...RuleOption) {
+	r := &abacRule{name: name, object: object, action: action, check: check}
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	z.mu.Lock()
+	defer z.mu.Unlock()
+
+	z.rules = append(z.rules, r)
+}
+
+// Authorize 用于进行授权，此时没有请求参数.
+func (z *ABACAuthorizer) Authorize(sub, obj, act string) (bool, error) {
+	return z.AuthorizeWithContext(context.Background(), sub, obj, act)
+}
+
+// AuthorizeWithContext 先进行 Casbin 路径授权，再执行所有适用的规则，请求参数从 ctx 中读取.
+// 规则拒绝访问时，审计记录中的 Policy 为 ["abac", 规则名称].
+func (z *ABACAuthorizer) AuthorizeWithContext(ctx context.Context, sub, obj, act string) (bool, error) {
+	allowed, policy, err := z.authz.enforce(sub, obj, act)
+	if err != nil {
+		return false, err
+	}
+
+	if allowed {
+		var rule string
+		if rule, err = z.evaluate(ctx, sub, obj, act); err != nil {
+			return false, err
+		}
+		if rule != "" {
+			allowed, policy = false, []string{"abac", rule}
+		}
+	}
+
+	z.authz.audit(ctx, &AuditRecord{Subject: sub, Object: obj, Action: act, Allowed: allowed, Policy: policy})
+
+	return allowed, nil
+}
+
+// evaluate 按注册顺序执行适用的规则，返回第一条拒绝访问的规则名称，全部通过时返回空字符串.
+func (z *ABACAuthorizer) evaluate(ctx context.Context, sub, obj, act string) (string, error) {
+	z.mu.RLock()
+	rules := z.rules
+	z.mu.RUnlock()
+
+	var attrs *Attributes
+	for _, r := range rules {
+		if !r.matches(obj, act) {
+			continue
+		}
+
+		// 每条规则使用独立的资源，避免规则之间共享加载结果
+		if attrs == nil {
+			attrs = &Attributes{Subject: sub, Object: obj, Action: act, Params: contextx.Params(ctx)}
+		}
+		attrs.Resource = nil
+		if r.loader != nil {
+			resource, err := r.loader(ctx, attrs)
+			if err != nil {
+				return "", err
+			}
+			attrs.Resource = resource
+		}
+
+		ok, err := r.check(ctx, attrs)
+		if err != nil {
+			return "", err
+		}
+		if !ok {
+			return r.name, nil
+		}
+	}
+
+	return "", nil
+}
+
+// OwnerRule 返回一条要求请求参数 param 等于 subject 的规则，例如只允许用户修改自己的资料：
+//
+//	abac.AddRule("self", "/v1/users/:userID", "PUT", authz.OwnerRule("userID"))
+func OwnerRule(param string) RuleFunc {
+	return func(ctx context.Context, attrs *Attributes) (bool, error) {
+		return attrs.Subject != "" && attrs.Param(param) == attrs.Subject, nil
+	}
+}
+
+// ResourceOwnerRule 返回一条要求 ResourceLoader 加载的资源属于 subject 的规则，owner 返回资源的所有者.
+// 需要和 WithResourceLoader 一起使用，资源为 nil 时拒绝访问.
+func ResourceOwnerRule(owner func(resource any) string) RuleFunc {
+	return func(ctx context.Context, attrs *Attributes) (bool, error) {
+		if attrs.Resource == nil {
+			return false, nil
+		}
+		return attrs.Subject != "" && owner(attrs.Resource) == attrs.Subject, nil
+	}
+}
@@ -0,0 +1,162 @@
+/**FileHeader
+ * @Author: Liangkang Zhang
+ * @Date: 2026/2/17 20:48:12
+ * @LastEditors: Liangkang Zhang
+ * @LastEditTime: 2026/2/17 20:48:12
+ * @Description:
+ * @Copyright: Copyright (©)}) 2026 Liangkang Zhang<lkzhang98@gmail.com>. All rights reserved. Use of this source code is governed by a MIT style license that can be found in the LICENSE file.. All rights reserved.
+ * @Email: lkzhang98@gmail.com
+ * @Repository: https://github.com/geminik12/autostack
+ */
+package authz
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"strings"
+	"time"
+
+	"github.com/geminik12/autostack/contextx"
+	"github.com/geminik12/autostack/log"
+	"github.com/geminik12/autostack/model"
+	"gorm.io/gorm"
+)
+
+// ErrAuditChannelFull 表示审计 channel 已满，审计记录被丢弃.
+var ErrAuditChannelFull = errors.New("audit channel is full")
+
+// AuditRecord 是一条授权决策的审计记录.
+type AuditRecord struct {
+	Subject string `json:"subject"`
+	// Tenant 是多租户授权时的租户 ID，单租户授权时为空.
+	Tenant  string `json:"tenant,omitempty"`
+	Object  string `json:"object"`
+	Action  string `json:"action"`
+	Allowed bool   `json:"allowed"`
+	// Policy 是产生该决策的策略，没有匹配任何策略时为空.
+	Policy []string `json:"policy,omitempty"`
+	// Error 是授权检查出错时的错误信息，此时 Allowed 为 false.
+	Error     string    `json:"error,omitempty"`
+	RequestID string    `json:"requestID,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// AuditSink 定义了授权审计记录的输出接口.
+type AuditSink interface {
+	// Record 保存一条审计记录，返回的错误只会被记录到日志，不会影响授权结果.
+	Record(ctx context.Context, record *AuditRecord) error
+}
+
+// WithAuditSink 设置授权审计记录的输出，每次授权决策都会写入 sink.
+func WithAuditSink(sink AuditSink) Option {
+	return func(cfg *authzConfig) {
+		cfg.auditSink = sink
+	}
+}
+
+// WithAuditAllowSampleRate 设置允许访问的决策的审计采样率，取值范围为 [0, 1]，默认为 1（全部记录）.
+// 拒绝访问的决策始终会被记录.
+func WithAuditAllowSampleRate(rate float64) Option {
+	return func(cfg *authzConfig) {
+		cfg.auditAllowSampleRate = min(max(rate, 0), 1)
+	}
+}
+
+// audit 按照采样配置将授权决策写入审计 sink.
+func (a *Authz) audit(ctx context.Context, record *AuditRecord) {
+	if a.auditSink == nil {
+		return
+	}
+
+	if record.Allowed && a.auditAllowSampleRate < 1 && rand.Float64() >= a.auditAllowSampleRate {
+		return
+	}
+
+	record.RequestID = contextx.RequestID(ctx)
+	record.Timestamp = time.Now()
+
+	if err := a.auditSink.Record(ctx, record); err != nil {
+		log.Errorw(err, "Failed to record authorization decision", "subject", record.Subject, "object", record.Object, "action", record.Action)
+	}
+}
+
+// LoggerAuditSink 将审计记录输出到日志.
+type LoggerAuditSink struct{}
+
+var _ AuditSink = LoggerAuditSink{}
+
+// NewLoggerAuditSink 创建一个将审计记录输出到日志的 sink.
+func NewLoggerAuditSink() LoggerAuditSink {
+	return LoggerAuditSink{}
+}
+
+// Record 将审计记录输出到日志.
+func (LoggerAuditSink) Record(ctx context.Context, record *AuditRecord) error {
+	log.W(ctx).Infow("Authorization decision",
+		"subject", record.Subject,
+		"tenant", record.Tenant,
+		"object", record.Object,
+		"action", record.Action,
+		"allowed", record.Allowed,
+		"policy", strings.Join(record.Policy, ", "),
+		"error", record.Error,
+		"requestID", record.RequestID,
+		"timestamp", record.Timestamp,
+	)
+	return nil
+}
+
+// GormAuditSink 将审计记录保存到数据库的 authz_audit 表.
+type GormAuditSink struct {
+	db *gorm.DB
+}
+
+var _ AuditSink = (*GormAuditSink)(nil)
+
+// NewGormAuditSink 创建一个将审计记录保存到数据库的 sink，authz_audit 表不存在时会自动创建.
+func NewGormAuditSink(db *gorm.DB) (*GormAuditSink, error) {
+	if err := db.AutoMigrate(&model.AuthzAuditM{}); err != nil {
+		return nil, err
+	}
+
+	return &GormAuditSink{db: db}, nil
+}
+
+// Record 将审计记录保存到数据库.
+func (s *GormAuditSink) Record(ctx context.Context, record *AuditRecord) error {
+	return s.db.WithContext(ctx).Create(&model.AuthzAuditM{
+		Subject:   record.Subject,
+		Tenant:    record.Tenant,
+		Object:    record.Object,
+		Action:    record.Action,
+		Allowed:   record.Allowed,
+		Policy:    strings.Join(record.Policy, ", "),
+		Error:     record.Error,
+		RequestID: record.RequestID,
+		CreatedAt: record.Timestamp,
+	}).Error
+}
+
+// ChannelAuditSink 将审计记录发送到 channel，由调用方异步消费.
+// channel 已满时丢弃记录并返回 ErrAuditChannelFull，不会阻塞授权.
+type ChannelAuditSink struct {
+	ch chan<- AuditRecord
+}
+
+var _ AuditSink = (*ChannelAuditSink)(nil)
+
+// NewChannelAuditSink 创建一个将审计记录发送到 ch 的 sink.
+func NewChannelAuditSink(ch chan<- AuditRecord) *ChannelAuditSink {
+	return &ChannelAuditSink{ch: ch}
+}
+
+// Record 将审计记录发送到 channel.
+func (s *ChannelAuditSink) Record(ctx context.Context, record *AuditRecord) error {
+	select {
+	case s.ch <- *record:
+		return nil
+	default:
+		return ErrAuditChannelFull
+	}
+}
@@ -11,10 +11,12 @@
 package authz
 
 import (
+	"context"
 	"time"
 
 	"github.com/casbin/casbin/v2"
 	"github.com/casbin/casbin/v2/model"
+	"github.com/casbin/casbin/v2/persist"
 	adapter "github.com/casbin/gorm-adapter/v3"
 	"github.com/google/wire"
 	"gorm.io/gorm"
@@ -41,6 +43,14 @@ m = g(r.sub, p.sub) && keyMatch(r.obj, p.obj) && r.act == p.act`
 // Authz 定义了一个授权器，提供授权功能.
 type Authz struct {
 	*casbin.SyncedEnforcer // 使用 Casbin 的同步授权器
+
+	watcher              persist.Watcher // 策略变更通知的 watcher，未配置时为 nil
+	cache                *decisionCache  // 授权决策缓存，未启用时为 nil
+	auditSink            AuditSink       // 授权审计记录的输出，未启用时为 nil
+	auditAllowSampleRate float64         // 允许访问的决策的审计采样率
+	// Casbin 没有提供读取以下开关的方法，通过 Authz 的同名方法设置时记录下来，临时关闭后据此恢复
+	autoSave          bool // 策略变更是否自动写入适配器
+	autoNotifyWatcher bool // 策略变更是否自动通知 watcher
 }
 
 // Option 定义了一个函数选项类型，用于自定义 NewAuthz 的行为.
@@ -48,8 +58,13 @@ type Option func(*authzConfig)
 
 // authzConfig 是授权器的配置结构.
 type authzConfig struct {
-	aclModel           string        // Casbin 的模型字符串
-	autoLoadPolicyTime time.Duration // 自动加载策略的时间间隔
+	aclModel             string          // Casbin 的模型字符串
+	autoLoadPolicyTime   time.Duration   // 自动加载策略的时间间隔
+	watcher              persist.Watcher // 策略变更通知的 watcher
+	cacheSize            int             // 授权决策缓存的容量，为 0 时不启用缓存
+	cacheTTL             time.Duration   // 授权决策缓存的有效期
+	auditSink            AuditSink       // 授权审计记录的输出
+	auditAllowSampleRate float64         // 允许访问的决策的审计采样率
 }
 
 // ProviderSet 是一个 Wire 的 Provider 集合，用于声明依赖注入的规则。
@@ -63,6 +78,8 @@ func defaultAuthzConfig() *authzConfig {
 		aclModel: defaultAclModel,
 		// 默认的自动加载策略时间间隔
 		autoLoadPolicyTime: 5 * time.Second,
+		// 默认记录全部允许访问的决策
+		auditAllowSampleRate: 1,
 	}
 }
 
@@ -84,12 +101,33 @@ func WithAclModel(model string) Option {
 }
 
 // WithAutoLoadPolicyTime 允许通过选项自定义自动加载策略的时间间隔.
+// interval 小于等于 0 时不启动定时加载，此时通常配合 WithWatcher 使用.
 func WithAutoLoadPolicyTime(interval time.Duration) Option {
 	return func(cfg *authzConfig) {
 		cfg.autoLoadPolicyTime = interval
 	}
 }
 
+// WithWatcher 设置策略变更通知的 watcher，例如 NewRedisWatcher 创建的 RedisWatcher.
+// 本实例修改策略后会通过 watcher 通知其他实例，其他实例收到通知后增量更新策略.
+// 配置 watcher 后定时加载策略只作为兜底，可以通过 WithAutoLoadPolicyTime 调大间隔或关闭.
+func WithWatcher(watcher persist.Watcher) Option {
+	return func(cfg *authzConfig) {
+		cfg.watcher = watcher
+	}
+}
+
+// WithDecisionCache 启用授权决策缓存，最多缓存 size 个 (sub, obj, act) 的决策，每个决策保留 ttl.
+// 通过 Enforcer 的 API 修改策略、收到其他实例的策略变更通知或重新加载策略时，缓存会自动失效.
+func WithDecisionCache(size int, ttl time.Duration) Option {
+	return func(cfg *authzConfig) {
+		if size > 0 && ttl > 0 {
+			cfg.cacheSize = size
+			cfg.cacheTTL = ttl
+		}
+	}
+}
+
 // NewAuthz 创建一个使用 Casbin 完成授权的授权器，通过函数选项模式支持自定义配置.
 func NewAuthz(db *gorm.DB, opts ...Option) (*Authz, error) {
 	// 初始化默认配置
@@ -120,15 +158,76 @@ func NewAuthz(db *gorm.DB, opts ...Option) (*Authz, error) {
 		return nil, err // 返回错误
 	}
 
+	a := &Authz{
+		SyncedEnforcer:       enforcer,
+		autoSave:             true,
+		autoNotifyWatcher:    true,
+		auditSink:            cfg.auditSink,
+		auditAllowSampleRate: cfg.auditAllowSampleRate,
+	}
+
+	// 启用决策缓存时包装 watcher，策略变更后使缓存失效
+	watcher := cfg.watcher
+	if cfg.cacheSize > 0 {
+		a.cache = newDecisionCache(cfg.cacheSize, cfg.cacheTTL)
+		watcher = &cacheWatcher{inner: cfg.watcher, authz: a}
+	}
+
+	// 设置 watcher，收到其他实例的通知后增量更新策略
+	if watcher != nil {
+		a.watcher = watcher
+		if err := enforcer.SetWatcher(watcher); err != nil {
+			return nil, err // 返回错误
+		}
+		if err := watcher.SetUpdateCallback(a.applyPolicyUpdate); err != nil {
+			return nil, err // 返回错误
+		}
+	}
+
 	// 启动自动加载策略，使用配置的时间间隔
-	enforcer.StartAutoLoadPolicy(cfg.autoLoadPolicyTime)
+	if cfg.autoLoadPolicyTime > 0 {
+		enforcer.StartAutoLoadPolicy(cfg.autoLoadPolicyTime)
+	}
 
 	// 返回新的授权器实例
-	return &Authz{enforcer}, nil
+	return a, nil
+}
+
+// EnableAutoSave 设置策略变更是否自动写入适配器，应通过该方法而不是 Casbin Enforcer 的同名方法设置.
+func (a *Authz) EnableAutoSave(autoSave bool) {
+	lock := a.GetLock()
+	lock.Lock()
+	defer lock.Unlock()
+
+	a.autoSave = autoSave
+	a.SyncedEnforcer.EnableAutoSave(autoSave)
+}
+
+// EnableAutoNotifyWatcher 设置策略变更是否自动通知 watcher，应通过该方法而不是 Casbin Enforcer 的同名方法设置.
+func (a *Authz) EnableAutoNotifyWatcher(enable bool) {
+	lock := a.GetLock()
+	lock.Lock()
+	defer lock.Unlock()
+
+	a.autoNotifyWatcher = enable
+	a.SyncedEnforcer.EnableAutoNotifyWatcher(enable)
 }
 
 // Authorize 用于进行授权.
 func (a *Authz) Authorize(sub, obj, act string) (bool, error) {
-	// 调用 Enforce 方法进行授权检查
-	return a.Enforce(sub, obj, act)
+	return a.AuthorizeWithContext(context.Background(), sub, obj, act)
+}
+
+// AuthorizeWithContext 与 Authorize 相同，审计记录中的请求 ID 从 ctx 中读取.
+func (a *Authz) AuthorizeWithContext(ctx context.Context, sub, obj, act string) (bool, error) {
+	// 调用 Enforce 方法进行授权检查，启用缓存时优先使用缓存的决策
+	allowed, policy, err := a.enforce(sub, obj, act)
+	if err != nil {
+		a.audit(ctx, &AuditRecord{Subject: sub, Object: obj, Action: act, Error: err.Error()})
+		return false, err
+	}
+
+	a.audit(ctx, &AuditRecord{Subject: sub, Object: obj, Action: act, Allowed: allowed, Policy: policy})
+
+	return allowed, nil
 }
@@ -0,0 +1,313 @@
+/**FileHeader
+ * @Author: Liangkang Zhang
+ * @Date: 2026/2/16 21:05:17
+ * @LastEditors: Liangkang Zhang
+ * @LastEditTime: 2026/2/16 21:05:17
+ * @Description:
+ * @Copyright: Copyright (©)}) 2026 Liangkang Zhang<lkzhang98@gmail.com>. All rights reserved. Use of this source code is governed by a MIT style license that can be found in the LICENSE file.. All rights reserved.
+ * @Email: lkzhang98@gmail.com
+ * @Repository: https://github.com/geminik12/autostack
+ */
+package authz
+
+import (
+	"container/list"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/casbin/casbin/v2/model"
+	"github.com/casbin/casbin/v2/persist"
+)
+
+// CacheStats 是授权决策缓存的统计信息.
+type CacheStats struct {
+	Hits   uint64 `json:"hits"`
+	Misses uint64 `json:"misses"`
+	Size   int    `json:"size"`
+}
+
+// decisionCache 是一个有容量上限和过期时间的 LRU 授权决策缓存.
+// 每个缓存项记录写入时的策略版本（generation），策略通过 Enforcer 修改后 generation 递增；
+// 策略重新加载后 Casbin 会替换模型，检测到模型被替换时 generation 同样递增，两种情况下旧的缓存项都会失效.
+type decisionCache struct {
+	size int
+	ttl  time.Duration
+
+	mu    sync.Mutex
+	ll    *list.List
+	items map[string]*list.Element
+	// model 是当前缓存项对应的模型，持有强引用保证旧模型在被替换前不会被回收，身份比较因此是可靠的
+	model model.Model
+
+	generation atomic.Uint64
+	hits       atomic.Uint64
+	misses     atomic.Uint64
+}
+
+// cacheEntry 是一个缓存项.
+type cacheEntry struct {
+	key        string
+	allowed    bool
+	policy     []string
+	generation uint64
+	expireAt   time.Time
+}
+
+// newDecisionCache 创建一个最多保存 size 个决策、每个决策保留 ttl 的缓存.
+func newDecisionCache(size int, ttl time.Duration) *decisionCache {
+	return &decisionCache{
+		size:  size,
+		ttl:   ttl,
+		ll:    list.New(),
+		items: make(map[string]*list.Element),
+	}
+}
+
+// get 查找缓存的决策及产生该决策的策略，缓存项已过期或策略版本不一致时视为未命中.
+func (c *decisionCache) get(key string, generation uint64) (bool, []string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.items[key]
+	if !ok {
+		c.misses.Add(1)
+		return false, nil, false
+	}
+
+	entry := elem.Value.(*cacheEntry)
+	if entry.generation != generation || time.Now().After(entry.expireAt) {
+		c.removeElement(elem)
+		c.misses.Add(1)
+		return false, nil, false
+	}
+
+	c.ll.MoveToFront(elem)
+	c.hits.Add(1)
+
+	return entry.allowed, entry.policy, true
+}
+
+// set 保存一个决策及产生该决策的策略，超出容量时淘汰最久未使用的缓存项.
+func (c *decisionCache) set(key string, allowed bool, policy []string, generation uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry := &cacheEntry{
+		key:        key,
+		allowed:    allowed,
+		policy:     policy,
+		generation: generation,
+		expireAt:   time.Now().Add(c.ttl),
+	}
+
+	if elem, ok := c.items[key]; ok {
+		elem.Value = entry
+		c.ll.MoveToFront(elem)
+		return
+	}
+
+	c.items[key] = c.ll.PushFront(entry)
+	for c.ll.Len() > c.size {
+		c.removeElement(c.ll.Back())
+	}
+}
+
+// invalidate 使所有缓存项失效.
+func (c *decisionCache) invalidate() {
+	c.generation.Add(1)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.ll.Init()
+	c.items = make(map[string]*list.Element)
+}
+
+// stats 返回缓存的统计信息.
+func (c *decisionCache) stats() CacheStats {
+	c.mu.Lock()
+	size := c.ll.Len()
+	c.mu.Unlock()
+
+	return CacheStats{Hits: c.hits.Load(), Misses: c.misses.Load(), Size: size}
+}
+
+// removeElement 删除一个缓存项，调用方需持有锁.
+func (c *decisionCache) removeElement(elem *list.Element) {
+	c.ll.Remove(elem)
+	delete(c.items, elem.Value.(*cacheEntry).key)
+}
+
+// observe 记录授权检查使用的模型并返回当前的策略版本.
+// Casbin 重新加载策略时会创建新的模型，模型被替换后递增 generation 并清空缓存.
+func (c *decisionCache) observe(m model.Model) uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.model == nil || !sameModel(c.model, m) {
+		c.model = m
+		c.generation.Add(1)
+		c.ll.Init()
+		c.items = make(map[string]*list.Element)
+	}
+
+	return c.generation.Load()
+}
+
+// sameModel 判断 a 和 b 是否为同一个模型. 两个模型都被引用，不会出现地址被复用的情况.
+func sameModel(a, b model.Model) bool {
+	return reflect.ValueOf(a).UnsafePointer() == reflect.ValueOf(b).UnsafePointer()
+}
+
+// enforce 执行授权检查并返回产生决策的策略，启用决策缓存时优先使用缓存的决策.
+func (a *Authz) enforce(rvals ...string) (bool, []string, error) {
+	params := make([]any, len(rvals))
+	for i, v := range rvals {
+		params[i] = v
+	}
+
+	if a.cache == nil {
+		return a.EnforceEx(params...)
+	}
+
+	// 持有 Enforcer 的读锁，保证读取到的策略版本与授权检查使用的策略一致
+	lock := a.GetLock()
+	lock.RLock()
+	defer lock.RUnlock()
+
+	key := strings.Join(rvals, "\x00")
+	generation := a.cache.observe(a.GetModel())
+
+	if allowed, policy, ok := a.cache.get(key, generation); ok {
+		return allowed, policy, nil
+	}
+
+	allowed, policy, err := a.SyncedEnforcer.Enforcer.EnforceEx(params...)
+	if err != nil {
+		return false, nil, err
+	}
+	a.cache.set(key, allowed, policy, generation)
+
+	return allowed, policy, nil
+}
+
+// CacheStats 返回授权决策缓存的命中和未命中次数，未启用缓存时返回零值.
+func (a *Authz) CacheStats() CacheStats {
+	if a.cache == nil {
+		return CacheStats{}
+	}
+	return a.cache.stats()
+}
+
+// InvalidateCache 使所有缓存的授权决策失效.
+// 通过 Enforcer 的 API 修改策略或重新加载策略时缓存会自动失效，
+// 直接修改模型等绕过 Enforcer 的场景需要手动调用.
+func (a *Authz) InvalidateCache() {
+	if a.cache != nil {
+		a.cache.invalidate()
+	}
+}
+
+// cacheWatcher 包装用户配置的 watcher，Enforcer 修改策略后使决策缓存失效，再通知其他实例.
+type cacheWatcher struct {
+	inner persist.Watcher
+	authz *Authz
+}
+
+var (
+	_ persist.WatcherEx        = (*cacheWatcher)(nil)
+	_ persist.UpdatableWatcher = (*cacheWatcher)(nil)
+)
+
+func (w *cacheWatcher) SetUpdateCallback(callback func(string)) error {
+	if w.inner == nil {
+		return nil
+	}
+	return w.inner.SetUpdateCallback(callback)
+}
+
+func (w *cacheWatcher) Update() error {
+	w.authz.InvalidateCache()
+	return w.update()
+}
+
+func (w *cacheWatcher) Close() {
+	if w.inner != nil {
+		w.inner.Close()
+	}
+}
+
+func (w *cacheWatcher) UpdateForAddPolicy(sec, ptype string, params ...string) error {
+	w.authz.InvalidateCache()
+	if ex, ok := w.inner.(persist.WatcherEx); ok {
+		return ex.UpdateForAddPolicy(sec, ptype, params...)
+	}
+	return w.update()
+}
+
+func (w *cacheWatcher) UpdateForRemovePolicy(sec, ptype string, params ...string) error {
+	w.authz.InvalidateCache()
+	if ex, ok := w.inner.(persist.WatcherEx); ok {
+		return ex.UpdateForRemovePolicy(sec, ptype, params...)
+	}
+	return w.update()
+}
+
+func (w *cacheWatcher) UpdateForRemoveFilteredPolicy(sec, ptype string, fieldIndex int, fieldValues ...string) error {
+	w.authz.InvalidateCache()
+	if ex, ok := w.inner.(persist.WatcherEx); ok {
+		return ex.UpdateForRemoveFilteredPolicy(sec, ptype, fieldIndex, fieldValues...)
+	}
+	return w.update()
+}
+
+func (w *cacheWatcher) UpdateForSavePolicy(model model.Model) error {
+	w.authz.InvalidateCache()
+	if ex, ok := w.inner.(persist.WatcherEx); ok {
+		return ex.UpdateForSavePolicy(model)
+	}
+	return w.update()
+}
+
+func (w *cacheWatcher) UpdateForAddPolicies(sec string, ptype string, rules ...[]string) error {
+	w.authz.InvalidateCache()
+	if ex, ok := w.inner.(persist.WatcherEx); ok {
+		return ex.UpdateForAddPolicies(sec, ptype, rules...)
+	}
+	return w.update()
+}
+
+func (w *cacheWatcher) UpdateForRemovePolicies(sec string, ptype string, rules ...[]string) error {
+	w.authz.InvalidateCache()
+	if ex, ok := w.inner.(persist.WatcherEx); ok {
+		return ex.UpdateForRemovePolicies(sec, ptype, rules...)
+	}
+	return w.update()
+}
+
+func (w *cacheWatcher) UpdateForUpdatePolicy(sec string, ptype string, oldRule, newRule []string) error {
+	w.authz.InvalidateCache()
+	if uw, ok := w.inner.(persist.UpdatableWatcher); ok {
+		return uw.UpdateForUpdatePolicy(sec, ptype, oldRule, newRule)
+	}
+	return w.update()
+}
+
+func (w *cacheWatcher) UpdateForUpdatePolicies(sec string, ptype string, oldRules, newRules [][]string) error {
+	w.authz.InvalidateCache()
+	if uw, ok := w.inner.(persist.UpdatableWatcher); ok {
+		return uw.UpdateForUpdatePolicies(sec, ptype, oldRules, newRules)
+	}
+	return w.update()
+}
+
+// update 通知内部 watcher 重新加载全部策略.
+func (w *cacheWatcher) update() error {
+	if w.inner == nil {
+		return nil
+	}
+	return w.inner.Update()
+}
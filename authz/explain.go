@@ -0,0 +1,80 @@
+/**FileHeader
+ * @Author: Liangkang Zhang
+ * @Date: 2026/2/18 21:12:40
+ * @LastEditors: Liangkang Zhang
+ * @LastEditTime: 2026/2/18 21:12:40
+ * @Description:
+ * @Copyright: Copyright (©)}) 2026 Liangkang Zhang<lkzhang98@gmail.com>. All rights reserved. Use of this source code is governed by a MIT style license that can be found in the LICENSE file.. All rights reserved.
+ * @Email: lkzhang98@gmail.com
+ * @Repository: https://github.com/geminik12/autostack
+ */
+package authz
+
+// Explanation 描述一次授权决策的依据.
+type Explanation struct {
+	Allowed bool `json:"allowed"`
+	// Policy 是产生该决策的策略规则，例如 ["role::admin", "/v1/users/*", "GET", "allow"].
+	// 没有匹配任何策略时为空，此时的决策由模型的 policy_effect 决定.
+	Policy []string `json:"policy,omitempty"`
+	// RoleLinks 是从 subject 到 Policy 中 subject 的角色继承链，例如 [["alice", "role::admin"]].
+	// Policy 直接授予 subject 或没有匹配任何策略时为空.
+	RoleLinks [][]string `json:"roleLinks,omitempty"`
+}
+
+// Explain 返回 sub 对 obj 执行 act 的授权决策以及产生该决策的策略规则和角色关系，用于排查 403 等授权问题.
+// Explain 不使用决策缓存，也不会写入审计记录.
+func (a *Authz) Explain(sub, obj, act string) (*Explanation, error) {
+	allowed, policy, err := a.EnforceEx(sub, obj, act)
+	if err != nil {
+		return nil, err
+	}
+
+	exp := &Explanation{Allowed: allowed, Policy: policy}
+	if len(policy) == 0 || policy[0] == sub {
+		return exp, nil
+	}
+
+	links, err := a.GetGroupingPolicy()
+	if err != nil {
+		return nil, err
+	}
+	exp.RoleLinks = roleChain(links, sub, policy[0])
+
+	return exp, nil
+}
+
+// roleChain 在角色关系中查找从 from 到 to 的最短继承链，找不到时返回 nil.
+func roleChain(links [][]string, from, to string) [][]string {
+	parents := make(map[string][][]string)
+	for _, link := range links {
+		if len(link) >= 2 {
+			parents[link[0]] = append(parents[link[0]], link)
+		}
+	}
+
+	// 广度优先搜索，prev 记录到达每个角色的角色关系
+	prev := map[string][]string{from: nil}
+	queue := []string{from}
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+
+		for _, link := range parents[name] {
+			role := link[1]
+			if _, seen := prev[role]; seen {
+				continue
+			}
+			prev[role] = link
+			if role == to {
+				var chain [][]string
+				for link := prev[to]; link != nil; link = prev[link[0]] {
+					chain = append([][]string{link}, chain...)
+				}
+				return chain
+			}
+			queue = append(queue, role)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,295 @@
+/**FileHeader
+ * @Author: Liangkang Zhang
+ * @Date: 2026/2/13 21:12:08
+ * @LastEditors: Liangkang Zhang
+ * @LastEditTime: 2026/2/13 21:12:08
+ * @Description:
+ * @Copyright: Copyright (©)}) 2026 Liangkang Zhang<lkzhang98@gmail.com>. All rights reserved. Use of this source code is governed by a MIT style license that can be found in the LICENSE file.. All rights reserved.
+ * @Email: lkzhang98@gmail.com
+ * @Repository: https://github.com/geminik12/autostack
+ */
+package authz
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/casbin/casbin/v2"
+	"github.com/casbin/casbin/v2/model"
+	"github.com/geminik12/autostack/known"
+)
+
+// RBACModel 是基于角色的访问控制模型，可以通过 WithAclModel 使用.
+// 与默认模型（只要没有拒绝策略即允许）不同，该模型要求请求至少匹配一条允许策略且没有匹配拒绝策略，
+// 因此未授予角色或策略的用户默认无权访问.
+const RBACModel = `[request_definition]
+r = sub, obj, act
+
+[policy_definition]
+p = sub, obj, act, eft
+
+[role_definition]
+g = _, _
+
+[policy_effect]
+e = some(where (p.eft == allow)) && !some(where (p.eft == deny))
+
+[matchers]
+m = g(r.sub, p.sub) && keyMatch(r.obj, p.obj) && r.act == p.act`
+
+const (
+	// EffectAllow 表示允许访问的策略效果.
+	EffectAllow = "allow"
+	// EffectDeny 表示拒绝访问的策略效果.
+	EffectDeny = "deny"
+)
+
+// ErrEmptyAdminUserID 表示 BootstrapAdmin 没有指定管理员的用户 ID.
+var ErrEmptyAdminUserID = errors.New("admin user id is empty")
+
+// defaultAdminActions 是 BootstrapAdmin 默认授予管理员角色的动作.
+var defaultAdminActions = []string{
+	http.MethodGet,
+	http.MethodPost,
+	http.MethodPut,
+	http.MethodPatch,
+	http.MethodDelete,
+	http.MethodHead,
+	http.MethodOptions,
+}
+
+// Policy 表示一条访问控制策略，对应 casbin_rule 表中 ptype 为 p 的一行.
+type Policy struct {
+	Subject string `json:"subject"`
+	Object  string `json:"object"`
+	Action  string `json:"action"`
+	Effect  string `json:"effect"`
+}
+
+// policyFromRule 将 Casbin 返回的策略规则转换为 Policy.
+func policyFromRule(rule []string) Policy {
+	var p Policy
+	fields := []*string{&p.Subject, &p.Object, &p.Action, &p.Effect}
+	for i := 0; i < len(rule) && i < len(fields); i++ {
+		*fields[i] = rule[i]
+	}
+	if p.Effect == "" {
+		p.Effect = EffectAllow
+	}
+	return p
+}
+
+// GrantRole 为用户授予角色，策略会通过 gorm 适配器持久化到 casbin_rule 表.
+func (a *Authz) GrantRole(user, role string) error {
+	_, err := a.AddRoleForUser(user, role)
+	return err
+}
+
+// RevokeRole 撤销用户的角色.
+func (a *Authz) RevokeRole(user, role string) error {
+	_, err := a.DeleteRoleForUser(user, role)
+	return err
+}
+
+// RolesOf 返回用户直接拥有的角色.
+func (a *Authz) RolesOf(user string) ([]string, error) {
+	return a.GetRolesForUser(user)
+}
+
+// UsersOf 返回直接拥有该角色的用户.
+func (a *Authz) UsersOf(role string) ([]string, error) {
+	return a.GetUsersForRole(role)
+}
+
+// AddRolePolicy 允许角色对 object 执行 action，object 支持 keyMatch 通配符，例如 /v1/users/*.
+func (a *Authz) AddRolePolicy(role, object, action string) error {
+	_, err := a.AddPolicy(role, object, action, EffectAllow)
+	return err
+}
+
+// DenyRolePolicy 禁止角色对 object 执行 action，拒绝策略优先于允许策略.
+func (a *Authz) DenyRolePolicy(role, object, action string) error {
+	_, err := a.AddPolicy(role, object, action, EffectDeny)
+	return err
+}
+
+// RemoveRolePolicy 删除角色对 object 执行 action 的策略，无论其效果是允许还是拒绝.
+func (a *Authz) RemoveRolePolicy(role, object, action string) error {
+	_, err := a.RemoveFilteredPolicy(0, role, object, action)
+	return err
+}
+
+// RolePolicies 返回直接授予角色的策略.
+func (a *Authz) RolePolicies(role string) ([]Policy, error) {
+	rules, err := a.GetFilteredPolicy(0, role)
+	if err != nil {
+		return nil, err
+	}
+
+	policies := make([]Policy, 0, len(rules))
+	for _, rule := range rules {
+		policies = append(policies, policyFromRule(rule))
+	}
+
+	return policies, nil
+}
+
+// EffectivePermissions 返回用户的有效权限，包括直接授予用户的策略以及通过角色（含角色继承）获得的策略.
+func (a *Authz) EffectivePermissions(user string) ([]Policy, error) {
+	rules, err := a.GetImplicitPermissionsForUser(user)
+	if err != nil {
+		return nil, err
+	}
+
+	policies := make([]Policy, 0, len(rules))
+	for _, rule := range rules {
+		policies = append(policies, policyFromRule(rule))
+	}
+
+	return policies, nil
+}
+
+// BootstrapAdmin 初始化默认策略：为 known.RoleAdmin 授予所有资源上的 actions，
+// 并将 adminUserID 加入 known.RoleAdmin. actions 为空时使用常用的 HTTP 方法.
+// 授权中间件使用用户 ID 作为 subject，因此 adminUserID 必须是管理员的用户 ID，而不是用户名.
+// 已存在的策略不会重复添加，可以在每次启动时调用.
+func (a *Authz) BootstrapAdmin(adminUserID string, actions ...string) error {
+	if adminUserID == "" {
+		return ErrEmptyAdminUserID
+	}
+
+	if len(actions) == 0 {
+		actions = defaultAdminActions
+	}
+
+	rules := make([][]string, 0, len(actions))
+	for _, action := range actions {
+		if ok, err := a.HasPolicy(known.RoleAdmin, "*", action, EffectAllow); err != nil {
+			return err
+		} else if !ok {
+			rules = append(rules, []string{known.RoleAdmin, "*", action, EffectAllow})
+		}
+	}
+
+	if len(rules) > 0 {
+		if _, err := a.AddPolicies(rules); err != nil {
+			return err
+		}
+	}
+
+	return a.GrantRole(adminUserID, known.RoleAdmin)
+}
+
+// HasMatchingPolicy 判断是否存在允许对 object 执行 action 的策略，不考虑策略的 subject，用于检查接口是否遗漏了策略.
+// object 可以是请求路径或路由模板，对象和动作的匹配方式与模型的 matcher 完全一致.
+//
+// 默认模型的 policy_effect 在没有匹配任何策略时也允许访问，因此不能直接使用授权结果判断. 这里使用相同的模型和
+// matcher、但 policy_effect 为"至少匹配一条允许策略"的 Enforcer，以每条允许策略的 subject（以及租户）发起请求，
+// 只有 EnforceEx 返回的匹配策略是允许策略时才认为 object 被覆盖.
+func (a *Authz) HasMatchingPolicy(object, action string) (bool, error) {
+	current := a.GetModel()
+	reqAst, ok := current["r"]["r"]
+	if !ok {
+		return false, nil
+	}
+	polAst, ok := current["p"]["p"]
+	if !ok {
+		return false, nil
+	}
+	eftIndex := slices.Index(polAst.Tokens, "p_eft")
+
+	rules, err := a.GetPolicy()
+	if err != nil {
+		return false, err
+	}
+
+	allowRules := make([][]string, 0, len(rules))
+	for _, rule := range rules {
+		if eftIndex >= 0 && eftIndex < len(rule) && rule[eftIndex] == EffectDeny {
+			continue
+		}
+		allowRules = append(allowRules, rule)
+	}
+	if len(allowRules) == 0 {
+		return false, nil
+	}
+
+	enforcer, err := coverageEnforcer(current, allowRules)
+	if err != nil {
+		return false, err
+	}
+
+	seen := make(map[string]struct{}, len(allowRules))
+	for _, rule := range allowRules {
+		// 按请求定义构造请求：对象和动作取待检查的值，其余字段（sub、dom 等）取策略中的同名字段
+		rvals, ok := policyRequest(reqAst.Tokens, polAst.Tokens, rule, object, action)
+		if !ok {
+			continue
+		}
+
+		key := strings.Join(rvals, "\x00")
+		if _, exists := seen[key]; exists {
+			continue
+		}
+		seen[key] = struct{}{}
+
+		args := make([]any, len(rvals))
+		for i, v := range rvals {
+			args[i] = v
+		}
+		allowed, explain, err := enforcer.EnforceEx(args...)
+		if err != nil {
+			return false, err
+		}
+		if allowed && len(explain) > 0 && (eftIndex < 0 || eftIndex >= len(explain) || explain[eftIndex] != EffectDeny) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// coverageEnforcer 创建一个只包含 rules 的内存 Enforcer，模型与 m 相同，
+// 但 policy_effect 为 some(where (p.eft == allow))，EnforceEx 会返回匹配的允许策略.
+func coverageEnforcer(m model.Model, rules [][]string) (*casbin.Enforcer, error) {
+	cm, err := model.NewModelFromString(m.ToText())
+	if err != nil {
+		return nil, err
+	}
+	cm.AddDef("e", "e", "some(where (p.eft == allow))")
+
+	enforcer, err := casbin.NewEnforcer(cm)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := enforcer.AddPolicies(rules); err != nil {
+		return nil, err
+	}
+
+	return enforcer, nil
+}
+
+// policyRequest 根据请求定义 reqTokens 构造请求，r_obj 和 r_act 使用 object 和 action，
+// 其余字段从策略 rule 中按同名的 p_* 字段读取，策略缺少对应字段时返回 false.
+func policyRequest(reqTokens, polTokens, rule []string, object, action string) ([]string, bool) {
+	rvals := make([]string, len(reqTokens))
+	for i, token := range reqTokens {
+		name := strings.TrimPrefix(token, "r_")
+		switch name {
+		case "obj":
+			rvals[i] = object
+		case "act":
+			rvals[i] = action
+		default:
+			index := slices.Index(polTokens, "p_"+name)
+			if index < 0 || index >= len(rule) {
+				return nil, false
+			}
+			rvals[i] = rule[index]
+		}
+	}
+
+	return rvals, true
+}
@@ -0,0 +1,61 @@
+package authz
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestHasMatchingPolicy(t *testing.T) {
+	tests := []struct {
+		name   string
+		model  string
+		object string
+		action string
+		want   bool
+	}{
+		{name: "default model covered", model: defaultAclModel, object: "/v1/users/:userID", action: http.MethodGet, want: true},
+		{name: "default model other action", model: defaultAclModel, object: "/v1/users/:userID", action: http.MethodDelete, want: false},
+		{name: "default model uncovered", model: defaultAclModel, object: "/v1/posts", action: http.MethodGet, want: false},
+		{name: "rbac model covered", model: RBACModel, object: "/v1/users/:userID", action: http.MethodGet, want: true},
+		{name: "rbac model uncovered", model: RBACModel, object: "/v1/posts", action: http.MethodGet, want: false},
+		{name: "keyMatch does not match route parameters", model: RBACModel, object: "/v1/orders/:orderID", action: http.MethodGet, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newTestAuthz(t, WithAclModel(tt.model))
+			if err := a.AddRolePolicy("viewer", "/v1/users/*", http.MethodGet); err != nil {
+				t.Fatalf("AddRolePolicy: %v", err)
+			}
+			// 路由参数名不同的策略在 keyMatch 下不匹配
+			if err := a.AddRolePolicy("viewer", "/v1/orders/:id", http.MethodGet); err != nil {
+				t.Fatalf("AddRolePolicy: %v", err)
+			}
+			if err := a.DenyRolePolicy("guest", "/v1/posts", http.MethodGet); err != nil {
+				t.Fatalf("DenyRolePolicy: %v", err)
+			}
+
+			got, err := a.HasMatchingPolicy(tt.object, tt.action)
+			if err != nil {
+				t.Fatalf("HasMatchingPolicy: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("HasMatchingPolicy(%s, %s) = %v, want %v", tt.object, tt.action, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHasMatchingPolicyInTenant(t *testing.T) {
+	a := newTestAuthz(t, WithAclModel(DomainRBACModel))
+	if err := a.AddTenantRolePolicy("viewer", "tenant-a", "/v1/users/*", http.MethodGet); err != nil {
+		t.Fatalf("AddTenantRolePolicy: %v", err)
+	}
+
+	if ok, err := a.HasMatchingPolicy("/v1/users/:userID", http.MethodGet); err != nil || !ok {
+		t.Errorf("HasMatchingPolicy covered route = %v, %v, want true", ok, err)
+	}
+	if ok, err := a.HasMatchingPolicy("/v1/posts", http.MethodGet); err != nil || ok {
+		t.Errorf("HasMatchingPolicy uncovered route = %v, %v, want false", ok, err)
+	}
+}
@@ -0,0 +1,121 @@
+/**FileHeader
+ * @Author: Liangkang Zhang
+ * @Date: 2026/2/14 20:48:31
+ * @LastEditors: Liangkang Zhang
+ * @LastEditTime: 2026/2/14 20:48:31
+ * @Description:
+ * @Copyright: Copyright (©)}) 2026 Liangkang Zhang<lkzhang98@gmail.com>. All rights reserved. Use of this source code is governed by a MIT style license that can be found in the LICENSE file.. All rights reserved.
+ * @Email: lkzhang98@gmail.com
+ * @Repository: https://github.com/geminik12/autostack
+ */
+package authz
+
+import "context"
+
+// DomainRBACModel 是支持多租户的基于角色的访问控制模型（RBAC with domains），可以通过 WithAclModel 使用.
+// 角色和策略都归属于某个租户，用户在租户 A 中被授予的角色不会在租户 B 中生效.
+// 使用该模型时应调用 AuthorizeInTenant 以及本文件中的 *InTenant 方法.
+const DomainRBACModel = `[request_definition]
+r = sub, dom, obj, act
+
+[policy_definition]
+p = sub, dom, obj, act, eft
+
+[role_definition]
+g = _, _, _
+
+[policy_effect]
+e = some(where (p.eft == allow)) && !some(where (p.eft == deny))
+
+[matchers]
+m = g(r.sub, p.sub, r.dom) && r.dom == p.dom && keyMatch(r.obj, p.obj) && r.act == p.act`
+
+// TenantPolicy 表示一条归属于租户的访问控制策略.
+type TenantPolicy struct {
+	Subject string `json:"subject"`
+	Tenant  string `json:"tenant"`
+	Object  string `json:"object"`
+	Action  string `json:"action"`
+	Effect  string `json:"effect"`
+}
+
+// tenantPolicyFromRule 将 Casbin 返回的策略规则转换为 TenantPolicy.
+func tenantPolicyFromRule(rule []string) TenantPolicy {
+	var p TenantPolicy
+	fields := []*string{&p.Subject, &p.Tenant, &p.Object, &p.Action, &p.Effect}
+	for i := 0; i < len(rule) && i < len(fields); i++ {
+		*fields[i] = rule[i]
+	}
+	if p.Effect == "" {
+		p.Effect = EffectAllow
+	}
+	return p
+}
+
+// AuthorizeInTenant 用于在租户 tenant 内进行授权，需要使用 DomainRBACModel.
+func (a *Authz) AuthorizeInTenant(sub, tenant, obj, act string) (bool, error) {
+	return a.AuthorizeInTenantWithContext(context.Background(), sub, tenant, obj, act)
+}
+
+// AuthorizeInTenantWithContext 与 AuthorizeInTenant 相同，审计记录中的请求 ID 从 ctx 中读取.
+func (a *Authz) AuthorizeInTenantWithContext(ctx context.Context, sub, tenant, obj, act string) (bool, error) {
+	allowed, policy, err := a.enforce(sub, tenant, obj, act)
+	if err != nil {
+		a.audit(ctx, &AuditRecord{Subject: sub, Tenant: tenant, Object: obj, Action: act, Error: err.Error()})
+		return false, err
+	}
+
+	a.audit(ctx, &AuditRecord{Subject: sub, Tenant: tenant, Object: obj, Action: act, Allowed: allowed, Policy: policy})
+
+	return allowed, nil
+}
+
+// GrantRoleInTenant 在租户 tenant 内为用户授予角色.
+func (a *Authz) GrantRoleInTenant(user, role, tenant string) error {
+	_, err := a.AddRoleForUserInDomain(user, role, tenant)
+	return err
+}
+
+// RevokeRoleInTenant 撤销用户在租户 tenant 内的角色.
+func (a *Authz) RevokeRoleInTenant(user, role, tenant string) error {
+	_, err := a.DeleteRoleForUserInDomain(user, role, tenant)
+	return err
+}
+
+// RolesInTenant 返回用户在租户 tenant 内直接拥有的角色.
+func (a *Authz) RolesInTenant(user, tenant string) []string {
+	return a.GetRolesForUserInDomain(user, tenant)
+}
+
+// AddTenantRolePolicy 允许角色在租户 tenant 内对 object 执行 action.
+func (a *Authz) AddTenantRolePolicy(role, tenant, object, action string) error {
+	_, err := a.AddPolicy(role, tenant, object, action, EffectAllow)
+	return err
+}
+
+// DenyTenantRolePolicy 禁止角色在租户 tenant 内对 object 执行 action.
+func (a *Authz) DenyTenantRolePolicy(role, tenant, object, action string) error {
+	_, err := a.AddPolicy(role, tenant, object, action, EffectDeny)
+	return err
+}
+
+// RemoveTenantRolePolicy 删除角色在租户 tenant 内对 object 执行 action 的策略.
+func (a *Authz) RemoveTenantRolePolicy(role, tenant, object, action string) error {
+	_, err := a.RemoveFilteredPolicy(0, role, tenant, object, action)
+	return err
+}
+
+// EffectivePermissionsInTenant 返回用户在租户 tenant 内的有效权限.
+func (a *Authz) EffectivePermissionsInTenant(user, tenant string) ([]TenantPolicy, error) {
+	rules, err := a.GetImplicitPermissionsForUser(user, tenant)
+	if err != nil {
+		return nil, err
+	}
+
+	policies := make([]TenantPolicy, 0, len(rules))
+	for _, rule := range rules {
+		policies = append(policies, tenantPolicyFromRule(rule))
+	}
+
+	return policies, nil
+}
@@ -0,0 +1,105 @@
+package authz
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// newTestAuthz 创建一个使用临时 SQLite 数据库的授权器.
+func newTestAuthz(t *testing.T, opts ...Option) *Authz {
+	t.Helper()
+
+	return newTestAuthzWithDB(t, openTestDB(t), opts...)
+}
+
+// openTestDB 打开一个临时的 SQLite 数据库.
+func openTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "authz.db")), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+
+	return db
+}
+
+// newTestAuthzWithDB 创建一个使用 db 保存策略的授权器，多个授权器共享 db 时可以模拟多个实例.
+func newTestAuthzWithDB(t *testing.T, db *gorm.DB, opts ...Option) *Authz {
+	t.Helper()
+
+	a, err := NewAuthz(db, append([]Option{WithAutoLoadPolicyTime(0)}, opts...)...)
+	if err != nil {
+		t.Fatalf("NewAuthz: %v", err)
+	}
+
+	return a
+}
+
+func TestAuthorizeInTenantIsolation(t *testing.T) {
+	a := newTestAuthz(t, WithAclModel(DomainRBACModel))
+
+	if err := a.AddTenantRolePolicy("editor", "tenant-a", "/v1/posts/*", "POST"); err != nil {
+		t.Fatalf("AddTenantRolePolicy: %v", err)
+	}
+	if err := a.AddTenantRolePolicy("editor", "tenant-b", "/v1/posts/*", "POST"); err != nil {
+		t.Fatalf("AddTenantRolePolicy: %v", err)
+	}
+	if err := a.GrantRoleInTenant("alice", "editor", "tenant-a"); err != nil {
+		t.Fatalf("GrantRoleInTenant: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		tenant string
+		want   bool
+	}{
+		{name: "granted tenant", tenant: "tenant-a", want: true},
+		{name: "other tenant", tenant: "tenant-b", want: false},
+		{name: "unknown tenant", tenant: "tenant-c", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			allowed, err := a.AuthorizeInTenant("alice", tt.tenant, "/v1/posts/1", "POST")
+			if err != nil {
+				t.Fatalf("AuthorizeInTenant: %v", err)
+			}
+			if allowed != tt.want {
+				t.Errorf("AuthorizeInTenant(tenant=%s) = %v, want %v", tt.tenant, allowed, tt.want)
+			}
+		})
+	}
+
+	if roles := a.RolesInTenant("alice", "tenant-b"); len(roles) != 0 {
+		t.Errorf("RolesInTenant(tenant-b) = %v, want none", roles)
+	}
+}
+
+func TestRevokeRoleInTenant(t *testing.T) {
+	a := newTestAuthz(t, WithAclModel(DomainRBACModel))
+
+	if err := a.AddTenantRolePolicy("editor", "tenant-a", "/v1/posts/*", "POST"); err != nil {
+		t.Fatalf("AddTenantRolePolicy: %v", err)
+	}
+	if err := a.GrantRoleInTenant("alice", "editor", "tenant-a"); err != nil {
+		t.Fatalf("GrantRoleInTenant: %v", err)
+	}
+	if err := a.RevokeRoleInTenant("alice", "editor", "tenant-a"); err != nil {
+		t.Fatalf("RevokeRoleInTenant: %v", err)
+	}
+
+	allowed, err := a.AuthorizeInTenant("alice", "tenant-a", "/v1/posts/1", "POST")
+	if err != nil {
+		t.Fatalf("AuthorizeInTenant: %v", err)
+	}
+	if allowed {
+		t.Error("AuthorizeInTenant after revoke = true, want false")
+	}
+}
@@ -0,0 +1,313 @@
+/**FileHeader
+ * @Author: Liangkang Zhang
+ * @Date: 2026/2/18 20:36:04
+ * @LastEditors: Liangkang Zhang
+ * @LastEditTime: 2026/2/18 20:36:04
+ * @Description:
+ * @Copyright: Copyright (©)}) 2026 Liangkang Zhang<lkzhang98@gmail.com>. All rights reserved. Use of this source code is governed by a MIT style license that can be found in the LICENSE file.. All rights reserved.
+ * @Email: lkzhang98@gmail.com
+ * @Repository: https://github.com/geminik12/autostack
+ */
+package authz
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/casbin/casbin/v2"
+	"github.com/casbin/casbin/v2/model"
+	adapter "github.com/casbin/gorm-adapter/v3"
+	"github.com/geminik12/autostack/log"
+	"github.com/goccy/go-yaml"
+)
+
+// PolicyFormat 是策略导入导出的文件格式.
+type PolicyFormat string
+
+const (
+	// FormatCSV 是 Casbin 策略文件的格式，每行为 "ptype, v0, v1, ..."，例如 "p, admin, /v1/users, GET".
+	FormatCSV PolicyFormat = "csv"
+	// FormatYAML 是按策略和角色关系分组的 YAML 格式，结构见 PolicySet.
+	FormatYAML PolicyFormat = "yaml"
+)
+
+// ImportMode 是策略导入的方式.
+type ImportMode string
+
+const (
+	// ImportModeReplace 删除现有的全部策略和角色关系，替换为导入的内容.
+	ImportModeReplace ImportMode = "replace"
+	// ImportModeMerge 保留现有的策略和角色关系，只添加尚不存在的规则.
+	ImportModeMerge ImportMode = "merge"
+)
+
+var (
+	// ErrUnsupportedPolicyFormat 表示不支持的策略文件格式.
+	ErrUnsupportedPolicyFormat = errors.New("unsupported policy format")
+	// ErrInvalidImportMode 表示不支持的策略导入方式.
+	ErrInvalidImportMode = errors.New("invalid import mode")
+	// ErrUnknownPolicyType 表示策略类型（ptype）没有在模型中定义.
+	ErrUnknownPolicyType = errors.New("unknown policy type")
+)
+
+// PolicyRule 是一条策略或角色关系规则.
+type PolicyRule struct {
+	// PType 是规则类型，例如策略为 "p"，角色关系为 "g".
+	PType string   `json:"ptype" yaml:"ptype"`
+	Rule  []string `json:"rule" yaml:"rule,flow"`
+}
+
+// PolicySet 是导入导出的全部策略和角色关系.
+type PolicySet struct {
+	Policies  []PolicyRule `json:"policies" yaml:"policies"`
+	RoleLinks []PolicyRule `json:"roleLinks" yaml:"roleLinks"`
+}
+
+// ExportPolicies 以 format 格式将全部策略和角色关系写入 w.
+func (a *Authz) ExportPolicies(w io.Writer, format PolicyFormat) error {
+	set, err := a.policySet()
+	if err != nil {
+		return err
+	}
+
+	switch format {
+	case FormatCSV:
+		return writePolicyCSV(w, set)
+	case FormatYAML:
+		return yaml.NewEncoder(w).Encode(set)
+	default:
+		return fmt.Errorf("%w: %s", ErrUnsupportedPolicyFormat, format)
+	}
+}
+
+// ImportPolicies 从 r 中读取 format 格式的策略和角色关系，按 mode 导入.
+// 导入在一个数据库事务中完成，任何一条规则导入失败时数据库和内存中的策略都保持不变.
+// 导入成功后会使决策缓存失效，并通过 watcher 通知其他实例重新加载策略（通过 EnableAutoNotifyWatcher 关闭时不通知）.
+func (a *Authz) ImportPolicies(r io.Reader, format PolicyFormat, mode ImportMode) error {
+	if mode != ImportModeReplace && mode != ImportModeMerge {
+		return fmt.Errorf("%w: %s", ErrInvalidImportMode, mode)
+	}
+
+	var set PolicySet
+	switch format {
+	case FormatCSV:
+		s, err := readPolicyCSV(r, a.GetModel())
+		if err != nil {
+			return err
+		}
+		set = *s
+	case FormatYAML:
+		if err := yaml.NewDecoder(r).Decode(&set); err != nil && !errors.Is(err, io.EOF) {
+			return err
+		}
+	default:
+		return fmt.Errorf("%w: %s", ErrUnsupportedPolicyFormat, format)
+	}
+
+	if err := a.importPolicySet(&set, mode); err != nil {
+		return err
+	}
+
+	// 导入过程中没有逐条通知 watcher，导入完成后通知其他实例重新加载全部策略，调用方关闭了自动通知时除外
+	lock := a.GetLock()
+	lock.RLock()
+	notify := a.autoNotifyWatcher
+	lock.RUnlock()
+
+	if a.watcher != nil && notify {
+		if err := a.watcher.Update(); err != nil {
+			log.Errorw(err, "Failed to notify policy watcher after import")
+		}
+	}
+
+	return nil
+}
+
+// policySet 返回当前的全部策略和角色关系，规则类型按名称排序.
+func (a *Authz) policySet() (*PolicySet, error) {
+	lock := a.GetLock()
+	lock.RLock()
+	defer lock.RUnlock()
+
+	e := a.SyncedEnforcer.Enforcer
+	m := e.GetModel()
+
+	set := &PolicySet{}
+	for _, ptype := range sortedKeys(m["p"]) {
+		rules, err := e.GetNamedPolicy(ptype)
+		if err != nil {
+			return nil, err
+		}
+		for _, rule := range rules {
+			set.Policies = append(set.Policies, PolicyRule{PType: ptype, Rule: rule})
+		}
+	}
+	for _, ptype := range sortedKeys(m["g"]) {
+		rules, err := e.GetNamedGroupingPolicy(ptype)
+		if err != nil {
+			return nil, err
+		}
+		for _, rule := range rules {
+			set.RoleLinks = append(set.RoleLinks, PolicyRule{PType: ptype, Rule: rule})
+		}
+	}
+
+	return set, nil
+}
+
+// importPolicySet 在数据库事务中导入策略和角色关系.
+func (a *Authz) importPolicySet(set *PolicySet, mode ImportMode) error {
+	// 导入期间持有 Enforcer 的写锁，授权检查和其他策略修改会等待导入完成
+	lock := a.GetLock()
+	lock.Lock()
+	defer lock.Unlock()
+
+	e := a.SyncedEnforcer.Enforcer
+	m := e.GetModel()
+
+	policies, err := groupRules(set.Policies, m["p"])
+	if err != nil {
+		return err
+	}
+	links, err := groupRules(set.RoleLinks, m["g"])
+	if err != nil {
+		return err
+	}
+
+	gormAdapter, ok := e.GetAdapter().(*adapter.Adapter)
+	if !ok {
+		return errors.New("policy import requires the gorm adapter")
+	}
+
+	// 使决策缓存失效需要在释放锁之前完成，避免读到导入前缓存的决策
+	defer a.InvalidateCache()
+
+	// 导入期间不逐条通知 watcher，导入完成后恢复调用方之前的设置
+	e.EnableAutoNotifyWatcher(false)
+	defer e.EnableAutoNotifyWatcher(a.autoNotifyWatcher)
+
+	// 事务失败时 Transaction 会回滚数据库并重新加载策略
+	return gormAdapter.Transaction(e, func(tx casbin.IEnforcer) error {
+		if mode == ImportModeReplace {
+			if err := removeAllRules(tx, m); err != nil {
+				return err
+			}
+		}
+
+		for _, ptype := range sortedKeys(policies) {
+			if _, err := tx.AddNamedPoliciesEx(ptype, policies[ptype]); err != nil {
+				return err
+			}
+		}
+		for _, ptype := range sortedKeys(links) {
+			if _, err := tx.AddNamedGroupingPoliciesEx(ptype, links[ptype]); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// removeAllRules 删除全部策略和角色关系.
+func removeAllRules(e casbin.IEnforcer, m model.Model) error {
+	for _, ptype := range sortedKeys(m["p"]) {
+		rules, err := e.GetNamedPolicy(ptype)
+		if err != nil {
+			return err
+		}
+		if len(rules) == 0 {
+			continue
+		}
+		if _, err := e.RemoveNamedPolicies(ptype, rules); err != nil {
+			return err
+		}
+	}
+	for _, ptype := range sortedKeys(m["g"]) {
+		rules, err := e.GetNamedGroupingPolicy(ptype)
+		if err != nil {
+			return err
+		}
+		if len(rules) == 0 {
+			continue
+		}
+		if _, err := e.RemoveNamedGroupingPolicies(ptype, rules); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// groupRules 按规则类型分组，规则类型必须在模型的对应部分中定义.
+func groupRules[V any](rules []PolicyRule, defined map[string]V) (map[string][][]string, error) {
+	grouped := make(map[string][][]string)
+	for _, r := range rules {
+		if _, ok := defined[r.PType]; !ok {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownPolicyType, r.PType)
+		}
+		grouped[r.PType] = append(grouped[r.PType], r.Rule)
+	}
+	return grouped, nil
+}
+
+// writePolicyCSV 以 Casbin 策略文件的格式写入策略和角色关系.
+func writePolicyCSV(w io.Writer, set *PolicySet) error {
+	cw := csv.NewWriter(w)
+	for _, r := range slices.Concat(set.Policies, set.RoleLinks) {
+		if err := cw.Write(append([]string{r.PType}, r.Rule...)); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// readPolicyCSV 读取 Casbin 策略文件格式的策略和角色关系，根据模型区分策略和角色关系.
+// 空行和以 # 开头的行会被忽略.
+func readPolicyCSV(r io.Reader, m model.Model) (*PolicySet, error) {
+	cr := csv.NewReader(r)
+	cr.Comment = '#'
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+
+	set := &PolicySet{}
+	for {
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		for i := range record {
+			record[i] = strings.TrimSpace(record[i])
+		}
+		rule := PolicyRule{PType: record[0], Rule: record[1:]}
+
+		if _, ok := m["p"][rule.PType]; ok {
+			set.Policies = append(set.Policies, rule)
+		} else if _, ok := m["g"][rule.PType]; ok {
+			set.RoleLinks = append(set.RoleLinks, rule)
+		} else {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownPolicyType, rule.PType)
+		}
+	}
+
+	return set, nil
+}
+
+// sortedKeys 返回按名称排序的键.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
@@ -0,0 +1,283 @@
+/**FileHeader
+ * @Author: Liangkang Zhang
+ * @Date: 2026/2/15 20:22:49
+ * @LastEditors: Liangkang Zhang
+ * @LastEditTime: 2026/2/15 20:22:49
+ * @Description:
+ * @Copyright: Copyright (©)}) 2026 Liangkang Zhang<lkzhang98@gmail.com>. All rights reserved. Use of this source code is governed by a MIT style license that can be found in the LICENSE file.. All rights reserved.
+ * @Email: lkzhang98@gmail.com
+ * @Repository: https://github.com/geminik12/autostack
+ */
+package authz
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+
+	"github.com/casbin/casbin/v2/model"
+	"github.com/casbin/casbin/v2/persist"
+	"github.com/geminik12/autostack/log"
+	"github.com/google/uuid"
+	redis "github.com/redis/go-redis/v9"
+)
+
+// 策略变更通知中的操作类型.
+const (
+	UpdateMethodUpdate               = "Update"
+	UpdateMethodAddPolicy            = "UpdateForAddPolicy"
+	UpdateMethodRemovePolicy         = "UpdateForRemovePolicy"
+	UpdateMethodRemoveFilteredPolicy = "UpdateForRemoveFilteredPolicy"
+	UpdateMethodSavePolicy           = "UpdateForSavePolicy"
+	UpdateMethodAddPolicies          = "UpdateForAddPolicies"
+	UpdateMethodRemovePolicies       = "UpdateForRemovePolicies"
+	UpdateMethodUpdatePolicy         = "UpdateForUpdatePolicy"
+	UpdateMethodUpdatePolicies       = "UpdateForUpdatePolicies"
+	defaultPolicyChannel             = "casbin:policy"
+)
+
+// PolicyMessage 是通过 Redis pub/sub 发布的策略变更通知.
+type PolicyMessage struct {
+	// ID 是发布通知的实例标识，实例会忽略自己发布的通知.
+	ID          string     `json:"id"`
+	Method      string     `json:"method"`
+	Sec         string     `json:"sec,omitempty"`
+	Ptype       string     `json:"ptype,omitempty"`
+	Rules       [][]string `json:"rules,omitempty"`
+	NewRules    [][]string `json:"newRules,omitempty"`
+	FieldIndex  int        `json:"fieldIndex,omitempty"`
+	FieldValues []string   `json:"fieldValues,omitempty"`
+}
+
+// WatcherOption 用于配置 RedisWatcher.
+type WatcherOption func(*RedisWatcher)
+
+// WithPolicyChannel 设置发布和订阅策略变更通知的 Redis 频道，默认为 "casbin:policy".
+func WithPolicyChannel(channel string) WatcherOption {
+	return func(w *RedisWatcher) {
+		if channel != "" {
+			w.channel = channel
+		}
+	}
+}
+
+// RedisWatcher 是基于 Redis pub/sub 的 Casbin watcher.
+// 本实例通过 Enforcer 修改策略后会发布增量变更通知，其他实例收到通知后只应用对应的变更，无需重新加载整张 casbin_rule 表.
+type RedisWatcher struct {
+	client  redis.UniversalClient
+	channel string
+	id      string
+
+	mu       sync.RWMutex
+	callback func(string)
+
+	pubsub *redis.PubSub
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+var (
+	_ persist.WatcherEx        = (*RedisWatcher)(nil)
+	_ persist.UpdatableWatcher = (*RedisWatcher)(nil)
+)
+
+// NewRedisWatcher 使用 db.NewRedis 创建的客户端构造 RedisWatcher，并开始订阅策略变更通知.
+// 通常通过 WithWatcher 传给 NewAuthz.
+func NewRedisWatcher(client redis.UniversalClient, opts ...WatcherOption) (*RedisWatcher, error) {
+	w := &RedisWatcher{
+		client:  client,
+		channel: defaultPolicyChannel,
+		id:      uuid.New().String(),
+		done:    make(chan struct{}),
+	}
+
+	for _, opt := range opts {
+		opt(w)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	w.cancel = cancel
+
+	// 等待订阅建立，确保之后发布的通知不会丢失
+	w.pubsub = client.Subscribe(ctx, w.channel)
+	if _, err := w.pubsub.Receive(ctx); err != nil {
+		cancel()
+		_ = w.pubsub.Close()
+		return nil, err
+	}
+
+	go w.subscribe()
+
+	return w, nil
+}
+
+// subscribe 接收其他实例发布的通知并调用回调函数.
+func (w *RedisWatcher) subscribe() {
+	defer close(w.done)
+
+	for msg := range w.pubsub.Channel() {
+		var m PolicyMessage
+		if err := json.Unmarshal([]byte(msg.Payload), &m); err != nil {
+			log.Errorw(err, "Failed to decode policy message", "channel", w.channel)
+			continue
+		}
+
+		// 忽略本实例发布的通知
+		if m.ID == w.id {
+			continue
+		}
+
+		w.mu.RLock()
+		callback := w.callback
+		w.mu.RUnlock()
+
+		if callback != nil {
+			callback(msg.Payload)
+		}
+	}
+}
+
+// SetUpdateCallback 设置收到其他实例的策略变更通知时调用的回调函数，参数为 JSON 编码的 PolicyMessage.
+func (w *RedisWatcher) SetUpdateCallback(callback func(string)) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.callback = callback
+	return nil
+}
+
+// Update 通知其他实例重新加载全部策略.
+func (w *RedisWatcher) Update() error {
+	return w.publish(PolicyMessage{Method: UpdateMethodUpdate})
+}
+
+// UpdateForAddPolicy 通知其他实例添加一条策略.
+func (w *RedisWatcher) UpdateForAddPolicy(sec, ptype string, params ...string) error {
+	return w.publish(PolicyMessage{Method: UpdateMethodAddPolicy, Sec: sec, Ptype: ptype, Rules: [][]string{params}})
+}
+
+// UpdateForRemovePolicy 通知其他实例删除一条策略.
+func (w *RedisWatcher) UpdateForRemovePolicy(sec, ptype string, params ...string) error {
+	return w.publish(PolicyMessage{Method: UpdateMethodRemovePolicy, Sec: sec, Ptype: ptype, Rules: [][]string{params}})
+}
+
+// UpdateForRemoveFilteredPolicy 通知其他实例按条件删除策略.
+func (w *RedisWatcher) UpdateForRemoveFilteredPolicy(sec, ptype string, fieldIndex int, fieldValues ...string) error {
+	return w.publish(PolicyMessage{
+		Method:      UpdateMethodRemoveFilteredPolicy,
+		Sec:         sec,
+		Ptype:       ptype,
+		FieldIndex:  fieldIndex,
+		FieldValues: fieldValues,
+	})
+}
+
+// UpdateForSavePolicy 通知其他实例重新加载全部策略.
+func (w *RedisWatcher) UpdateForSavePolicy(model model.Model) error {
+	return w.publish(PolicyMessage{Method: UpdateMethodSavePolicy})
+}
+
+// UpdateForAddPolicies 通知其他实例添加多条策略.
+func (w *RedisWatcher) UpdateForAddPolicies(sec string, ptype string, rules ...[]string) error {
+	return w.publish(PolicyMessage{Method: UpdateMethodAddPolicies, Sec: sec, Ptype: ptype, Rules: rules})
+}
+
+// UpdateForRemovePolicies 通知其他实例删除多条策略.
+func (w *RedisWatcher) UpdateForRemovePolicies(sec string, ptype string, rules ...[]string) error {
+	return w.publish(PolicyMessage{Method: UpdateMethodRemovePolicies, Sec: sec, Ptype: ptype, Rules: rules})
+}
+
+// UpdateForUpdatePolicy 通知其他实例更新一条策略.
+func (w *RedisWatcher) UpdateForUpdatePolicy(sec string, ptype string, oldRule, newRule []string) error {
+	return w.publish(PolicyMessage{
+		Method:   UpdateMethodUpdatePolicy,
+		Sec:      sec,
+		Ptype:    ptype,
+		Rules:    [][]string{oldRule},
+		NewRules: [][]string{newRule},
+	})
+}
+
+// UpdateForUpdatePolicies 通知其他实例更新多条策略.
+func (w *RedisWatcher) UpdateForUpdatePolicies(sec string, ptype string, oldRules, newRules [][]string) error {
+	return w.publish(PolicyMessage{Method: UpdateMethodUpdatePolicies, Sec: sec, Ptype: ptype, Rules: oldRules, NewRules: newRules})
+}
+
+// Close 停止订阅策略变更通知.
+func (w *RedisWatcher) Close() {
+	w.cancel()
+	_ = w.pubsub.Close()
+	<-w.done
+}
+
+// publish 发布一条策略变更通知.
+func (w *RedisWatcher) publish(m PolicyMessage) error {
+	m.ID = w.id
+
+	data, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+
+	return w.client.Publish(context.Background(), w.channel, data).Err()
+}
+
+// applyPolicyUpdate 将其他实例发布的策略变更应用到本实例，只更新变更的部分.
+// 无法识别的通知会退化为重新加载全部策略.
+// 发起变更的实例已经写入了数据库，这里只更新内存中的模型，不会再次写入数据库：
+// 否则迟到的"添加"通知可能把发起实例已经删除的策略重新写回数据库.
+func (a *Authz) applyPolicyUpdate(payload string) {
+	var m PolicyMessage
+	if err := json.Unmarshal([]byte(payload), &m); err != nil {
+		m.Method = UpdateMethodUpdate
+	}
+
+	// 增量更新不会经过 watcher，需要手动使决策缓存失效
+	defer a.InvalidateCache()
+
+	reload, err := a.applyPolicyMessage(&m)
+	if err != nil {
+		// 增量更新失败时重新加载全部策略，保证与数据库一致
+		log.Errorw(err, "Failed to apply policy update, reload all policies", "method", m.Method)
+		reload = true
+	}
+
+	if reload {
+		if err := a.LoadPolicy(); err != nil {
+			log.Errorw(err, "Failed to reload policies")
+		}
+	}
+}
+
+// applyPolicyMessage 在持有 Enforcer 写锁、关闭自动保存的情况下将策略变更应用到内存中的模型.
+// 返回 true 表示无法增量更新，需要重新加载全部策略.
+func (a *Authz) applyPolicyMessage(m *PolicyMessage) (bool, error) {
+	lock := a.GetLock()
+	lock.Lock()
+	defer lock.Unlock()
+
+	// Self* 方法不会通知 watcher，但会在开启自动保存时写入适配器
+	e := a.SyncedEnforcer.Enforcer
+	e.EnableAutoSave(false)
+	defer e.EnableAutoSave(a.autoSave)
+
+	var err error
+	switch m.Method {
+	case UpdateMethodAddPolicy, UpdateMethodAddPolicies:
+		_, err = e.SelfAddPoliciesEx(m.Sec, m.Ptype, m.Rules)
+	case UpdateMethodRemovePolicy, UpdateMethodRemovePolicies:
+		_, err = e.SelfRemovePolicies(m.Sec, m.Ptype, m.Rules)
+	case UpdateMethodRemoveFilteredPolicy:
+		_, err = e.SelfRemoveFilteredPolicy(m.Sec, m.Ptype, m.FieldIndex, m.FieldValues...)
+	case UpdateMethodUpdatePolicy, UpdateMethodUpdatePolicies:
+		var ok bool
+		// 本实例中找不到旧策略时无法增量更新，重新加载全部策略
+		if ok, err = e.SelfUpdatePolicies(m.Sec, m.Ptype, m.Rules, m.NewRules); err == nil && !ok {
+			return true, nil
+		}
+	default:
+		return true, nil
+	}
+
+	return false, err
+}
@@ -0,0 +1,61 @@
+package authz
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+// publishTo 模拟 a 收到其他实例发布的策略变更通知.
+func publishTo(t *testing.T, a *Authz, m PolicyMessage) {
+	t.Helper()
+
+	payload, err := json.Marshal(m)
+	if err != nil {
+		t.Fatalf("marshal message: %v", err)
+	}
+	a.applyPolicyUpdate(string(payload))
+}
+
+func TestApplyPolicyUpdateDoesNotWriteAdapter(t *testing.T) {
+	db := openTestDB(t)
+	origin := newTestAuthzWithDB(t, db, WithAclModel(RBACModel))
+	replica := newTestAuthzWithDB(t, db, WithAclModel(RBACModel))
+
+	rule := []string{"viewer", "/v1/users/*", http.MethodGet, EffectAllow}
+
+	// 发起实例添加后又删除了策略，副本迟到地收到"添加"通知
+	if err := origin.AddRolePolicy("viewer", "/v1/users/*", http.MethodGet); err != nil {
+		t.Fatalf("AddRolePolicy: %v", err)
+	}
+	if err := origin.RemoveRolePolicy("viewer", "/v1/users/*", http.MethodGet); err != nil {
+		t.Fatalf("RemoveRolePolicy: %v", err)
+	}
+	publishTo(t, replica, PolicyMessage{Method: UpdateMethodAddPolicy, Sec: "p", Ptype: "p", Rules: [][]string{rule}})
+
+	// 副本只更新内存中的模型
+	if ok, err := replica.HasPolicy(rule); err != nil || !ok {
+		t.Errorf("replica HasPolicy after add message = %v, %v, want true", ok, err)
+	}
+
+	// 数据库中不应重新出现已删除的策略
+	if err := origin.LoadPolicy(); err != nil {
+		t.Fatalf("LoadPolicy: %v", err)
+	}
+	if ok, err := origin.HasPolicy(rule); err != nil || ok {
+		t.Errorf("origin HasPolicy after reload = %v, %v, want false", ok, err)
+	}
+
+	// 删除通知同样不写入数据库，之后重新加载的副本与数据库一致
+	if err := origin.AddRolePolicy("editor", "/v1/posts/*", http.MethodPost); err != nil {
+		t.Fatalf("AddRolePolicy: %v", err)
+	}
+	editor := []string{"editor", "/v1/posts/*", http.MethodPost, EffectAllow}
+	publishTo(t, replica, PolicyMessage{Method: UpdateMethodRemovePolicy, Sec: "p", Ptype: "p", Rules: [][]string{editor}})
+	if err := replica.LoadPolicy(); err != nil {
+		t.Fatalf("LoadPolicy: %v", err)
+	}
+	if ok, err := replica.HasPolicy(editor); err != nil || !ok {
+		t.Errorf("replica HasPolicy after reload = %v, %v, want true", ok, err)
+	}
+}
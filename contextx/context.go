@@ -22,6 +22,14 @@ type (
 	accessTokenKey struct{}
 	// requestIDKey 定义请求 ID 的上下文键.
 	requestIDKey struct{}
+	// claimsKey 定义 token claims 的上下文键.
+	claimsKey struct{}
+	// tenantIDKey 定义租户 ID 的上下文键.
+	tenantIDKey struct{}
+	// paramsKey 定义请求参数的上下文键.
+	paramsKey struct{}
+	// scopesKey 定义授权范围的上下文键.
+	scopesKey struct{}
 )
 
 // WithUserID 将用户 ID 存放到上下文中.
@@ -35,6 +43,17 @@ func UserID(ctx context.Context) string {
 	return userID
 }
 
+// WithTenantID 将租户 ID 存放到上下文中.
+func WithTenantID(ctx context.Context, tenantID string) context.Context {
+	return context.WithValue(ctx, tenantIDKey{}, tenantID)
+}
+
+// TenantID 从上下文中提取租户 ID.
+func TenantID(ctx context.Context) string {
+	tenantID, _ := ctx.Value(tenantIDKey{}).(string)
+	return tenantID
+}
+
 // WithUsername 将用户名存放到上下文中.
 func WithUsername(ctx context.Context, username string) context.Context {
 	return context.WithValue(ctx, usernameKey{}, username)
@@ -67,3 +86,36 @@ func RequestID(ctx context.Context) string {
 	requestID, _ := ctx.Value(requestIDKey{}).(string)
 	return requestID
 }
+
+// WithClaims 将解析后的 token claims 存放到上下文中.
+// claims 可以是 jwt.MapClaims，也可以是 token.ParseTyped 返回的强类型 claims.
+func WithClaims(ctx context.Context, claims any) context.Context {
+	return context.WithValue(ctx, claimsKey{}, claims)
+}
+
+// Claims 从上下文中提取 token claims，需要强类型 claims 时使用 token.ClaimsFromContext.
+func Claims(ctx context.Context) any {
+	return ctx.Value(claimsKey{})
+}
+
+// WithParams 将请求参数存放到上下文中，HTTP 请求为路由参数（例如 :userID），gRPC 请求为请求消息中的字段.
+func WithParams(ctx context.Context, params map[string]string) context.Context {
+	return context.WithValue(ctx, paramsKey{}, params)
+}
+
+// Params 从上下文中提取请求参数.
+func Params(ctx context.Context) map[string]string {
+	params, _ := ctx.Value(paramsKey{}).(map[string]string)
+	return params
+}
+
+// WithScopes 将凭证的授权范围存放到上下文中，例如 API Key 的 scopes.
+func WithScopes(ctx context.Context, scopes []string) context.Context {
+	return context.WithValue(ctx, scopesKey{}, scopes)
+}
+
+// Scopes 从上下文中提取授权范围.
+func Scopes(ctx context.Context) []string {
+	scopes, _ := ctx.Value(scopesKey{}).([]string)
+	return scopes
+}
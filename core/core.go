@@ -12,11 +12,14 @@ package core
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"net/http"
 
 	"github.com/geminik12/autostack/binding"
 	"github.com/geminik12/autostack/errorsx"
 	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
 )
 
 // Validator 是验证函数的类型，用于对绑定的数据结构进行验证.
@@ -37,6 +40,8 @@ type ErrorResponse struct {
 	Message string `json:"message,omitempty"`
 	// 附带的元数据信息
 	Metadata map[string]string `json:"metadata,omitempty"`
+	// 请求字段的校验错误
+	Violations []errorsx.FieldViolation `json:"violations,omitempty"`
 }
 
 // HandleAllRequest 是处理综合请求的快捷函数。
@@ -106,7 +111,7 @@ func ShouldBindUri[T any](c *gin.Context, rq *T, validators ...Validator[T]) err
 // 它能覆盖同名字段（后者优先），并支持 Default() 与验证函数 validators。
 func ShouldBindAll[T any](c *gin.Context, rq *T, validators ...Validator[T]) error {
 	if err := binding.Bind(c, rq, binding.URI, binding.JSON); err != nil {
-		return errorsx.ErrBind.WithMessage(err.Error())
+		return bindError(err)
 	}
 
 	// 应用 Default() 并执行验证逻辑
@@ -124,7 +129,7 @@ func ShouldBindAll[T any](c *gin.Context, rq *T, validators ...Validator[T]) err
 func ReadRequest[T any](c *gin.Context, rq *T, binder Binder, validators ...Validator[T]) error {
 	// 调用绑定函数绑定请求数据
 	if err := binder(rq); err != nil {
-		return errorsx.ErrBind.WithMessage(err.Error())
+		return bindError(err)
 	}
 
 	if err := FinalizeRequest(c, rq, validators...); err != nil {
@@ -134,6 +139,26 @@ func ReadRequest[T any](c *gin.Context, rq *T, binder Binder, validators ...Vali
 	return nil
 }
 
+// bindError 将绑定错误转换为 errorsx.ErrBind，字段校验失败时通过 Violations 返回每个字段的错误.
+func bindError(err error) error {
+	var verrs validator.ValidationErrors
+	if !errors.As(err, &verrs) {
+		return errorsx.ErrBind.WithMessage("%s", err.Error())
+	}
+
+	// 使用新的错误实例，避免字段错误残留在共享的 ErrBind 上
+	violations := make([]errorsx.FieldViolation, 0, len(verrs))
+	for _, fe := range verrs {
+		message := fmt.Sprintf("failed on the '%s' validation", fe.Tag())
+		if fe.Param() != "" {
+			message = fmt.Sprintf("failed on the '%s=%s' validation", fe.Tag(), fe.Param())
+		}
+		violations = append(violations, errorsx.FieldViolation{Field: fe.Field(), Message: message})
+	}
+
+	return errorsx.New(errorsx.ErrBind.Code, errorsx.ErrBind.Reason, "%s", err.Error()).WithViolations(violations...)
+}
+
 // FinalizeRequest 在请求参数绑定完成后执行以下操作：
 // 1. 如果目标类型实现了 Default() 方法，则调用 Default 设置默认值；
 // 2. 顺序执行所有验证函数。
@@ -158,18 +183,42 @@ func FinalizeRequest[T any](c *gin.Context, rq *T, validators ...Validator[T]) e
 
 // WriteResponse 是通用的响应函数.
 // 它会根据是否发生错误，生成成功响应或标准化的错误响应.
+// 响应格式根据 Accept 请求头协商，是否使用统一的包装结构或 Problem Details 格式由 ConfigureResponse 配置.
+// Protobuf 格式的响应不会被包装：成功时直接写入 proto.Message，错误时写入 google.rpc.Status.
 func WriteResponse(c *gin.Context, data any, err error) {
+	o := currentResponseOptions.Load()
+	format := o.negotiate(c)
+
 	if err != nil {
 		// 如果发生错误，生成错误响应
 		errx := errorsx.FromError(err) // 提取错误详细信息
-		c.JSON(errx.Code, ErrorResponse{
-			Reason:   errx.Reason,
-			Message:  errx.Message,
-			Metadata: errx.Metadata,
-		})
+		if format == FormatProtobuf {
+			writeFormat(c, format, errx.Code, errx.GRPCStatus().Proto())
+			return
+		}
+		if o.problemTypeBase != "" {
+			writeProblem(c, format, newProblemDetails(c, o.problemTypeBase, errx))
+			return
+		}
+
+		errResp := &ErrorResponse{
+			Reason:     errx.Reason,
+			Message:    errx.Message,
+			Metadata:   errx.Metadata,
+			Violations: errx.Violations,
+		}
+
+		var resp any = errResp
+		if o.envelope != nil {
+			resp = o.envelope(c, errx.Code, nil, errResp)
+		}
+		writeFormat(c, format, errx.Code, resp)
 		return
 	}
 
 	// 如果没有错误，返回成功响应
-	c.JSON(http.StatusOK, data)
+	if o.envelope != nil && !(format == FormatProtobuf && isProtoMessage(data)) {
+		data = o.envelope(c, http.StatusOK, data, nil)
+	}
+	writeFormat(c, format, http.StatusOK, data)
 }
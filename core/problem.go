@@ -0,0 +1,117 @@
+/**FileHeader
+ * @Author: Liangkang Zhang
+ * @Date: 2026/2/26 20:34:09
+ * @LastEditors: Liangkang Zhang
+ * @LastEditTime: 2026/2/26 20:34:09
+ * @Description:
+ * @Copyright: Copyright (©)}) 2026 Liangkang Zhang<lkzhang98@gmail.com>. All rights reserved. Use of this source code is governed by a MIT style license that can be found in the LICENSE file.. All rights reserved.
+ * @Email: lkzhang98@gmail.com
+ * @Repository: https://github.com/geminik12/autostack
+ */
+package core
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+
+	"github.com/geminik12/autostack/errorsx"
+	"github.com/gin-gonic/gin"
+)
+
+// ContentTypeProblemJSON 是 RFC 9457 Problem Details 的 JSON 媒体类型.
+const ContentTypeProblemJSON = "application/problem+json"
+
+// defaultProblemTypeBase 是没有设置 type 前缀时使用的前缀.
+const defaultProblemTypeBase = "urn:problem-type:"
+
+// problemMembers 是 Problem Details 的标准成员以及字段校验错误成员，扩展成员不能使用这些名称.
+var problemMembers = []string{"type", "title", "status", "detail", "instance", "errors"}
+
+// ProblemDetails 是 RFC 9457 定义的错误响应结构.
+type ProblemDetails struct {
+	// Type 是标识错误类型的 URI，由 type 前缀和 errorsx.ErrorX 的 Reason 组成
+	Type string
+	// Title 是错误类型的简短描述，为 HTTP 状态码对应的状态文本
+	Title string
+	// Status 是 HTTP 状态码
+	Status int
+	// Detail 是本次错误的描述，来自 errorsx.ErrorX 的 Message
+	Detail string
+	// Instance 是发生错误的请求路径
+	Instance string
+	// Errors 是请求字段的校验错误
+	Errors []errorsx.FieldViolation
+	// Extensions 是扩展成员，来自 errorsx.ErrorX 的 Metadata，与标准成员同名的键会被忽略
+	Extensions map[string]string
+}
+
+// WithProblemDetails 使用 RFC 9457 Problem Details 格式返回错误响应，JSON 格式的 Content-Type 为 application/problem+json.
+// 错误类型 type 为 typeBase 加上 errorsx.ErrorX 的 Reason，例如 typeBase 为 "https://example.com/problems/" 时，
+// ErrNotFound 的 type 为 "https://example.com/problems/NotFound"；typeBase 为空时使用 "urn:problem-type:".
+// 开启后错误响应不再使用 WithEnvelope 配置的包装结构，成功响应不受影响.
+func WithProblemDetails(typeBase string) ResponseOption {
+	return func(o *responseOptions) {
+		if typeBase == "" {
+			typeBase = defaultProblemTypeBase
+		}
+		o.problemTypeBase = typeBase
+	}
+}
+
+// newProblemDetails 根据 errx 构造请求 c 的 Problem Details.
+func newProblemDetails(c *gin.Context, typeBase string, errx *errorsx.ErrorX) *ProblemDetails {
+	problem := &ProblemDetails{
+		Type:       "about:blank",
+		Title:      http.StatusText(errx.Code),
+		Status:     errx.Code,
+		Detail:     errx.Message,
+		Instance:   c.Request.URL.Path,
+		Errors:     errx.Violations,
+		Extensions: errx.Metadata,
+	}
+	if errx.Reason != "" {
+		problem.Type = typeBase + errx.Reason
+	}
+
+	return problem
+}
+
+// Members 返回 Problem Details 的全部成员，扩展成员和标准成员位于同一层级.
+func (p *ProblemDetails) Members() map[string]any {
+	members := make(map[string]any, len(p.Extensions)+6)
+	for k, v := range p.Extensions {
+		if !slices.Contains(problemMembers, k) {
+			members[k] = v
+		}
+	}
+
+	members["type"] = p.Type
+	members["title"] = p.Title
+	members["status"] = p.Status
+	if p.Detail != "" {
+		members["detail"] = p.Detail
+	}
+	if p.Instance != "" {
+		members["instance"] = p.Instance
+	}
+	if len(p.Errors) > 0 {
+		members["errors"] = p.Errors
+	}
+
+	return members
+}
+
+// MarshalJSON 实现 json.Marshaler 接口.
+func (p *ProblemDetails) MarshalJSON() ([]byte, error) {
+	return json.Marshal(p.Members())
+}
+
+// writeProblem 按 format 格式写入 Problem Details.
+func writeProblem(c *gin.Context, format string, problem *ProblemDetails) {
+	if format == FormatJSON {
+		// Content-Type 已设置时 gin 不会再覆盖
+		c.Header("Content-Type", ContentTypeProblemJSON)
+	}
+	writeFormat(c, format, problem.Status, problem.Members())
+}
@@ -0,0 +1,170 @@
+/**FileHeader
+ * @Author: Liangkang Zhang
+ * @Date: 2026/2/25 20:18:44
+ * @LastEditors: Liangkang Zhang
+ * @LastEditTime: 2026/2/25 20:18:44
+ * @Description:
+ * @Copyright: Copyright (©)}) 2026 Liangkang Zhang<lkzhang98@gmail.com>. All rights reserved. Use of this source code is governed by a MIT style license that can be found in the LICENSE file.. All rights reserved.
+ * @Email: lkzhang98@gmail.com
+ * @Repository: https://github.com/geminik12/autostack
+ */
+package core
+
+import (
+	"encoding/json"
+	"sync/atomic"
+
+	"github.com/geminik12/autostack/contextx"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/render"
+	"github.com/goccy/go-yaml"
+	"google.golang.org/protobuf/proto"
+)
+
+// 响应支持的格式，客户端通过 Accept 请求头选择.
+const (
+	FormatJSON     = "application/json"
+	FormatProtobuf = "application/x-protobuf"
+	FormatMsgPack  = "application/x-msgpack"
+	FormatYAML     = "application/x-yaml"
+)
+
+// formatAliases 是同一种格式的其他常见 MIME 类型.
+var formatAliases = map[string][]string{
+	FormatJSON:    {ContentTypeProblemJSON},
+	FormatMsgPack: {"application/msgpack"},
+	FormatYAML:    {"application/yaml"},
+}
+
+// Envelope 是统一响应包装的结构，成功时 Data 为响应数据，失败时 Error 为错误信息.
+type Envelope struct {
+	// Code 是响应的 HTTP 状态码
+	Code int `json:"code"`
+	// Data 是成功时的响应数据
+	Data any `json:"data,omitempty"`
+	// Error 是失败时的错误信息
+	Error *ErrorResponse `json:"error,omitempty"`
+	// RequestID 是请求 ID，来自 contextx.RequestID
+	RequestID string `json:"request_id,omitempty"`
+}
+
+// EnvelopeFunc 根据响应构造自定义的包装结构，err 为 nil 表示成功.
+type EnvelopeFunc func(c *gin.Context, code int, data any, err *ErrorResponse) any
+
+// ResponseOption 用于配置 WriteResponse.
+type ResponseOption func(*responseOptions)
+
+// responseOptions 是 WriteResponse 的配置.
+type responseOptions struct {
+	envelope        EnvelopeFunc
+	formats         []string
+	problemTypeBase string
+}
+
+// WithEnvelope 使用 Envelope 包装所有响应，包括成功和失败的响应.
+func WithEnvelope() ResponseOption {
+	return WithEnvelopeFunc(func(c *gin.Context, code int, data any, err *ErrorResponse) any {
+		return &Envelope{Code: code, Data: data, Error: err, RequestID: contextx.RequestID(c.Request.Context())}
+	})
+}
+
+// WithEnvelopeFunc 使用 fn 构造的自定义结构包装所有响应.
+func WithEnvelopeFunc(fn EnvelopeFunc) ResponseOption {
+	return func(o *responseOptions) {
+		o.envelope = fn
+	}
+}
+
+// WithFormats 设置可以协商的响应格式，第一个格式为默认格式，默认为 JSON、Protobuf、MessagePack 和 YAML.
+// 客户端请求的格式都不支持时使用 JSON.
+func WithFormats(formats ...string) ResponseOption {
+	return func(o *responseOptions) {
+		if len(formats) > 0 {
+			o.formats = formats
+		}
+	}
+}
+
+// defaultResponseOptions 返回 WriteResponse 的默认配置：不包装响应，支持全部格式.
+func defaultResponseOptions() *responseOptions {
+	return &responseOptions{formats: []string{FormatJSON, FormatProtobuf, FormatMsgPack, FormatYAML}}
+}
+
+// currentResponseOptions 是 WriteResponse 当前使用的配置.
+var currentResponseOptions atomic.Pointer[responseOptions]
+
+func init() {
+	currentResponseOptions.Store(defaultResponseOptions())
+}
+
+// ConfigureResponse 设置 WriteResponse 的配置，未设置的选项使用默认值.
+// 通常在服务启动时调用一次，所有通过 WriteResponse 和 Handle*Request 返回的响应都会使用该配置.
+func ConfigureResponse(opts ...ResponseOption) {
+	o := defaultResponseOptions()
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	currentResponseOptions.Store(o)
+}
+
+// negotiate 根据 Accept 请求头选择响应格式.
+func (o *responseOptions) negotiate(c *gin.Context) string {
+	offered := make([]string, 0, len(o.formats)*2)
+	for _, format := range o.formats {
+		offered = append(offered, format)
+		offered = append(offered, formatAliases[format]...)
+	}
+
+	accepted := c.NegotiateFormat(offered...)
+	for _, format := range o.formats {
+		if accepted == format {
+			return format
+		}
+		for _, alias := range formatAliases[format] {
+			if accepted == alias {
+				return format
+			}
+		}
+	}
+
+	return FormatJSON
+}
+
+// writeFormat 按 format 格式写入响应.
+// Protobuf 格式只能写入 proto.Message，其他数据使用 JSON 格式写入；YAML 格式使用 JSON 的字段名，转换失败时使用 JSON 格式写入.
+func writeFormat(c *gin.Context, format string, code int, obj any) {
+	switch format {
+	case FormatProtobuf:
+		if isProtoMessage(obj) {
+			c.ProtoBuf(code, obj)
+			return
+		}
+	case FormatMsgPack:
+		c.Render(code, render.MsgPack{Data: obj})
+		return
+	case FormatYAML:
+		if data, err := jsonToYAML(obj); err == nil {
+			c.Data(code, "application/yaml; charset=utf-8", data)
+			return
+		}
+	}
+
+	c.JSON(code, obj)
+}
+
+// jsonToYAML 先将 obj 编码为 JSON 再转换为 YAML，使 YAML 响应与 JSON 响应的结构一致：
+// 字段名来自 json 标签，嵌入的结构体字段被展开，json.Marshaler 和 json.RawMessage 按 JSON 的结果输出.
+func jsonToYAML(obj any) ([]byte, error) {
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+	return yaml.JSONToYAML(data)
+}
+
+// isProtoMessage 判断 data 是否为 proto.Message.
+func isProtoMessage(data any) bool {
+	_, ok := data.(proto.Message)
+	return ok
+}
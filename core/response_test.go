@@ -0,0 +1,81 @@
+package core
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/geminik12/autostack/errorsx"
+	"github.com/gin-gonic/gin"
+)
+
+func TestWriteResponseYAMLKeys(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ConfigureResponse(WithEnvelope())
+	t.Cleanup(func() { ConfigureResponse() })
+
+	type audit struct {
+		CreatedBy string `json:"createdBy"`
+	}
+	type user struct {
+		audit
+		UserID   string          `json:"userID"`
+		Nickname string          `json:"nickname"`
+		Password string          `json:"-"`
+		Extra    json.RawMessage `json:"extra"`
+	}
+
+	tests := []struct {
+		name   string
+		accept string
+		data   any
+		err    error
+		want   []string
+	}{
+		{
+			name:   "success",
+			accept: FormatYAML,
+			data: &user{
+				audit:    audit{CreatedBy: "admin"},
+				UserID:   "user-1",
+				Nickname: "alice",
+				Password: "secret",
+				Extra:    json.RawMessage(`{"theme":"dark"}`),
+			},
+			want: []string{"code: 200", "data:", "userID: user-1", "nickname: alice", "createdBy: admin", "theme: dark"},
+		},
+		{
+			name:   "error",
+			accept: "application/yaml",
+			err:    errorsx.ErrInvalidArgument.WithMessage("bad phone").WithViolations(errorsx.FieldViolation{Field: "phone", Message: "invalid"}),
+			want:   []string{"code: 400", "error:", "reason: InvalidArgument", "message: bad phone", "violations:", "field: phone"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+			c.Request.Header.Set("Accept", tt.accept)
+
+			WriteResponse(c, tt.data, tt.err)
+
+			if ct := w.Header().Get("Content-Type"); !strings.Contains(ct, "yaml") {
+				t.Fatalf("Content-Type = %q, want YAML", ct)
+			}
+			body := w.Body.String()
+			for _, want := range tt.want {
+				if !strings.Contains(body, want) {
+					t.Errorf("body missing %q:\n%s", want, body)
+				}
+			}
+			for _, unwanted := range []string{"userid:", "requestid:", "audit:", "password:", "secret", "- 123"} {
+				if strings.Contains(body, unwanted) {
+					t.Errorf("body contains %q:\n%s", unwanted, body)
+				}
+			}
+		})
+	}
+}
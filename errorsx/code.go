@@ -44,4 +44,7 @@ var (
 
 	// ErrOperationFailed 表示操作失败.
 	ErrOperationFailed = &ErrorX{Code: http.StatusConflict, Reason: "OperationFailed", Message: "The requested operation has failed. Please try again later."}
+
+	// ErrTooManyRequests 表示请求过于频繁，例如连续认证失败后被暂时锁定.
+	ErrTooManyRequests = &ErrorX{Code: http.StatusTooManyRequests, Reason: "TooManyRequests", Message: "Too many requests. Please try again later."}
 )
@@ -18,6 +18,7 @@ import (
 	httpstatus "github.com/go-kratos/kratos/v2/transport/http/status"
 	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
 )
 
 // ErrorX 定义了 OneX 项目体系中使用的错误类型，用于描述错误的详细信息.
@@ -33,6 +34,17 @@ type ErrorX struct {
 
 	// Metadata 用于存储与该错误相关的额外元信息，可以包含上下文或调试信息.
 	Metadata map[string]string `json:"metadata,omitempty"`
+
+	// Violations 是请求字段的校验错误，用于说明哪些字段不合法.
+	Violations []FieldViolation `json:"violations,omitempty"`
+}
+
+// FieldViolation 描述一个请求字段的校验错误.
+type FieldViolation struct {
+	// Field 是不合法的字段名称.
+	Field string `json:"field"`
+	// Message 是字段不合法的原因.
+	Message string `json:"message"`
 }
 
 // New 创建一个新的错误.
@@ -61,6 +73,12 @@ func (err *ErrorX) WithMetadata(md map[string]string) *ErrorX {
 	return err
 }
 
+// WithViolations 设置请求字段的校验错误.
+func (err *ErrorX) WithViolations(violations ...FieldViolation) *ErrorX {
+	err.Violations = violations
+	return err
+}
+
 // KV 使用 key-value 对设置元数据.
 func (err *ErrorX) KV(kvs ...string) *ErrorX {
 	if err.Metadata == nil {
@@ -78,8 +96,16 @@ func (err *ErrorX) KV(kvs ...string) *ErrorX {
 
 // GRPCStatus 返回 gRPC 状态表示.
 func (err *ErrorX) GRPCStatus() *status.Status {
-	details := errdetails.ErrorInfo{Reason: err.Reason, Metadata: err.Metadata}
-	s, _ := status.New(httpstatus.ToGRPCCode(err.Code), err.Message).WithDetails(&details)
+	details := []protoadapt.MessageV1{&errdetails.ErrorInfo{Reason: err.Reason, Metadata: err.Metadata}}
+	if len(err.Violations) > 0 {
+		badRequest := &errdetails.BadRequest{}
+		for _, v := range err.Violations {
+			badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{Field: v.Field, Description: v.Message})
+		}
+		details = append(details, badRequest)
+	}
+
+	s, _ := status.New(httpstatus.ToGRPCCode(err.Code), err.Message).WithDetails(details...)
 	return s
 }
 
@@ -132,18 +158,23 @@ func FromError(err error) *ErrorX {
 	// 则返回一个带有默认值的 ErrorX，表示是一个未知类型的错误.
 	gs, ok := status.FromError(err)
 	if !ok {
-		return New(ErrInternal.Code, ErrInternal.Reason, err.Error())
+		return New(ErrInternal.Code, ErrInternal.Reason, "%s", err.Error())
 	}
 
 	// 如果 err 是 gRPC 的错误类型，会成功返回一个 gRPC status 对象（gs）.
 	// 使用 gRPC 状态中的错误代码和消息创建一个 ErrorX.
-	ret := New(httpstatus.FromGRPCCode(gs.Code()), ErrInternal.Reason, gs.Message())
+	ret := New(httpstatus.FromGRPCCode(gs.Code()), ErrInternal.Reason, "%s", gs.Message())
 
 	// 遍历 gRPC 错误详情中的所有附加信息（Details）.
 	for _, detail := range gs.Details() {
-		if typed, ok := detail.(*errdetails.ErrorInfo); ok {
+		switch typed := detail.(type) {
+		case *errdetails.ErrorInfo:
 			ret.Reason = typed.Reason
-			return ret.WithMetadata(typed.Metadata)
+			ret.WithMetadata(typed.Metadata)
+		case *errdetails.BadRequest:
+			for _, v := range typed.GetFieldViolations() {
+				ret.Violations = append(ret.Violations, FieldViolation{Field: v.GetField(), Message: v.GetDescription()})
+			}
 		}
 	}
 
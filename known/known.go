@@ -27,6 +27,12 @@ const (
 
 	// XUsername 用来定义上下文的键，代表请求用户名.
 	XUsername = "x-username"
+
+	// XTenantID 用来定义上下文的键，代表请求所属的租户 ID.
+	XTenantID = "x-tenant-id"
+
+	// XAPIKey 用来定义携带 API Key 的 Header.
+	XAPIKey = "x-api-key"
 )
 
 // 定义其他常量.
@@ -0,0 +1,160 @@
+/**FileHeader
+ * @Author: Liangkang Zhang
+ * @Date: 2026/2/23 21:47:22
+ * @LastEditors: Liangkang Zhang
+ * @LastEditTime: 2026/2/23 21:47:22
+ * @Description:
+ * @Copyright: Copyright (©)}) 2026 Liangkang Zhang<lkzhang98@gmail.com>. All rights reserved. Use of this source code is governed by a MIT style license that can be found in the LICENSE file.. All rights reserved.
+ * @Email: lkzhang98@gmail.com
+ * @Repository: https://github.com/geminik12/autostack
+ */
+package mfa
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/geminik12/autostack/contextx"
+	"github.com/geminik12/autostack/core"
+	"github.com/geminik12/autostack/errorsx"
+	"github.com/geminik12/autostack/token"
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// ConfirmRequest 是完成绑定接口的请求参数.
+type ConfirmRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// ConfirmResponse 是完成绑定接口的响应.
+type ConfirmResponse struct {
+	RecoveryCodes []string `json:"recoveryCodes"`
+}
+
+// VerifyRequest 是多因素认证接口的请求参数，Code 和 RecoveryCode 二选一.
+type VerifyRequest struct {
+	Code         string `json:"code"`
+	RecoveryCode string `json:"recoveryCode"`
+}
+
+// VerifyResponse 是多因素认证接口的响应.
+type VerifyResponse struct {
+	Token    string    `json:"token"`
+	ExpireAt time.Time `json:"expireAt"`
+}
+
+// HandlerOption 用于配置 MFA 处理函数.
+type HandlerOption func(*handlerOptions)
+
+// handlerOptions 是 MFA 处理函数的配置.
+type handlerOptions struct {
+	manager *token.Manager
+}
+
+// WithTokenManager 设置签发 token 使用的 token.Manager，默认使用 token 包的默认 Manager.
+func WithTokenManager(manager *token.Manager) HandlerOption {
+	return func(o *handlerOptions) {
+		o.manager = manager
+	}
+}
+
+// EnrollHandler 返回一个 Gin 处理函数，为当前用户生成 TOTP 密钥和 otpauth URI，需要放在认证中间件之后.
+func EnrollHandler(m *Manager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+		enrollment, err := m.Enroll(ctx, contextx.UserID(ctx), contextx.Username(ctx))
+		if err != nil {
+			core.WriteResponse(c, nil, toErrorX(err))
+			return
+		}
+
+		core.WriteResponse(c, enrollment, nil)
+	}
+}
+
+// ConfirmHandler 返回一个 Gin 处理函数，使用验证码完成当前用户的绑定并返回恢复码，需要放在认证中间件之后.
+func ConfirmHandler(m *Manager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		core.HandleJSONRequest(c, func(ctx context.Context, rq *ConfirmRequest) (*ConfirmResponse, error) {
+			codes, err := m.Confirm(ctx, contextx.UserID(ctx), rq.Code)
+			if err != nil {
+				return nil, toErrorX(err)
+			}
+
+			return &ConfirmResponse{RecoveryCodes: codes}, nil
+		})
+	}
+}
+
+// VerifyHandler 返回一个 Gin 处理函数，校验当前用户提交的验证码或恢复码，
+// 通过后基于当前 token 的 claims 签发一个带有 ClaimMFA 标记的新 token. 需要放在认证中间件之后.
+func VerifyHandler(m *Manager, opts ...HandlerOption) gin.HandlerFunc {
+	o := &handlerOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return func(c *gin.Context) {
+		core.HandleJSONRequest(c, func(ctx context.Context, rq *VerifyRequest) (*VerifyResponse, error) {
+			userID := contextx.UserID(ctx)
+
+			var err error
+			switch {
+			case rq.Code != "":
+				err = m.Verify(ctx, userID, rq.Code)
+			case rq.RecoveryCode != "":
+				err = m.VerifyRecoveryCode(ctx, userID, rq.RecoveryCode)
+			default:
+				return nil, errorsx.ErrInvalidArgument.WithMessage("code or recoveryCode is required")
+			}
+			if err != nil {
+				return nil, toErrorX(err)
+			}
+
+			current, ok := contextx.Claims(ctx).(jwt.MapClaims)
+			if !ok {
+				return nil, errorsx.ErrTokenInvalid.WithMessage("token claims not found")
+			}
+
+			// 保留当前会话的 claims，jti 和时间字段由 SignWithClaims 重新生成
+			claims := make(jwt.MapClaims, len(current)+2)
+			for k, v := range current {
+				switch k {
+				case "jti", "nbf", "iat", "exp":
+				default:
+					claims[k] = v
+				}
+			}
+			claims[ClaimMFA] = true
+			claims[ClaimMFAAt] = time.Now().Unix()
+
+			manager := o.manager
+			if manager == nil {
+				manager = token.Default()
+			}
+
+			tokenString, expireAt, err := manager.SignWithClaims(claims)
+			if err != nil {
+				return nil, errorsx.ErrSignToken.WithMessage("%s", err.Error())
+			}
+
+			return &VerifyResponse{Token: tokenString, ExpireAt: expireAt}, nil
+		})
+	}
+}
+
+// toErrorX 将 MFA 错误转换为对应的 API 错误.
+func toErrorX(err error) error {
+	switch {
+	case errors.Is(err, ErrInvalidCode), errors.Is(err, ErrCodeReused):
+		return errorsx.ErrUnauthenticated.WithMessage("%s", err.Error())
+	case errors.Is(err, ErrTooManyAttempts):
+		return errorsx.ErrTooManyRequests.WithMessage("%s", err.Error())
+	case errors.Is(err, ErrNotEnrolled), errors.Is(err, ErrNotEnabled), errors.Is(err, ErrAlreadyEnabled):
+		return errorsx.ErrOperationFailed.WithMessage("%s", err.Error())
+	default:
+		return errorsx.ErrInternal.WithMessage("%s", err.Error())
+	}
+}
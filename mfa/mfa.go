@@ -0,0 +1,354 @@
+/**FileHeader
+ * @Author: Liangkang Zhang
+ * @Date: 2026/2/23 21:14:05
+ * @LastEditors: Liangkang Zhang
+ * @LastEditTime: 2026/2/23 21:14:05
+ * @Description:
+ * @Copyright: Copyright (©)}) 2026 Liangkang Zhang<lkzhang98@gmail.com>. All rights reserved. Use of this source code is governed by a MIT style license that can be found in the LICENSE file.. All rights reserved.
+ * @Email: lkzhang98@gmail.com
+ * @Repository: https://github.com/geminik12/autostack
+ */
+package mfa
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/geminik12/autostack/model"
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// MFA 相关的预定义错误
+var (
+	ErrNotEnrolled     = errors.New("mfa is not enrolled")
+	ErrNotEnabled      = errors.New("mfa is not enabled")
+	ErrAlreadyEnabled  = errors.New("mfa is already enabled")
+	ErrInvalidCode     = errors.New("invalid verification code")
+	ErrCodeReused      = errors.New("verification code has already been used")
+	ErrTooManyAttempts = errors.New("too many failed verification attempts, try again later")
+)
+
+const (
+	// ClaimMFA 是标记会话已完成多因素认证的 token claim，值为 true.
+	ClaimMFA = "mfa"
+	// ClaimMFAAt 是完成多因素认证的 Unix 时间戳.
+	ClaimMFAAt = "mfa_at"
+)
+
+const (
+	defaultDigits            = 6
+	defaultPeriod            = 30 * time.Second
+	defaultSkew              = 1
+	defaultRecoveryCodeCount = 10
+	defaultMaxAttempts       = 5
+	defaultLockout           = 15 * time.Minute
+)
+
+// Option 用于配置 Manager.
+type Option func(*Manager)
+
+// WithIssuer 设置 otpauth URI 中的签发者，认证器应用会将其显示为账号的名称前缀.
+func WithIssuer(issuer string) Option {
+	return func(m *Manager) {
+		m.issuer = issuer
+	}
+}
+
+// WithDigits 设置验证码的位数，默认为 6 位.
+func WithDigits(digits int) Option {
+	return func(m *Manager) {
+		if digits > 0 {
+			m.digits = digits
+		}
+	}
+}
+
+// WithPeriod 设置验证码的时间步长，默认为 30 秒.
+func WithPeriod(period time.Duration) Option {
+	return func(m *Manager) {
+		if period >= time.Second {
+			m.period = period
+		}
+	}
+}
+
+// WithSkew 设置允许的时钟偏差，即当前时间步前后各允许多少个时间步，默认为 1.
+func WithSkew(skew int) Option {
+	return func(m *Manager) {
+		if skew >= 0 {
+			m.skew = skew
+		}
+	}
+}
+
+// WithRecoveryCodeCount 设置每次生成的恢复码数量，默认为 10 个.
+func WithRecoveryCodeCount(n int) Option {
+	return func(m *Manager) {
+		if n > 0 {
+			m.recoveryCodeCount = n
+		}
+	}
+}
+
+// WithMaxAttempts 设置连续验证失败多少次后锁定用户，TOTP 验证码和恢复码共用一个计数，默认为 5 次.
+func WithMaxAttempts(n int) Option {
+	return func(m *Manager) {
+		if n > 0 {
+			m.maxAttempts = n
+		}
+	}
+}
+
+// WithLockout 设置连续验证失败达到上限后的锁定时长，锁定期间的验证请求直接返回 ErrTooManyAttempts，默认为 15 分钟.
+func WithLockout(lockout time.Duration) Option {
+	return func(m *Manager) {
+		if lockout > 0 {
+			m.lockout = lockout
+		}
+	}
+}
+
+// Enrollment 是开始绑定时返回给用户的密钥信息.
+type Enrollment struct {
+	// Secret 是 base32 编码的 TOTP 密钥，用于用户手动输入.
+	Secret string `json:"secret"`
+	// URI 是 otpauth URI，通常编码为二维码供认证器应用扫描.
+	URI string `json:"uri"`
+}
+
+// Manager 负责 TOTP（RFC 6238）的绑定、验证和恢复码管理.
+// 绑定分为两步：Enroll 生成密钥，用户在认证器应用中添加后调用 Confirm 提交一个验证码完成绑定.
+// 每个时间步的验证码只能使用一次，恢复码也只能使用一次. 连续验证失败达到上限后用户会被暂时锁定，防止暴力破解.
+type Manager struct {
+	store             Store
+	issuer            string
+	digits            int
+	period            time.Duration
+	skew              int
+	recoveryCodeCount int
+	maxAttempts       int
+	lockout           time.Duration
+}
+
+// NewManager 创建一个使用 store 保存绑定信息的 Manager.
+func NewManager(store Store, opts ...Option) *Manager {
+	m := &Manager{
+		store:             store,
+		digits:            defaultDigits,
+		period:            defaultPeriod,
+		skew:              defaultSkew,
+		recoveryCodeCount: defaultRecoveryCodeCount,
+		maxAttempts:       defaultMaxAttempts,
+		lockout:           defaultLockout,
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	return m
+}
+
+// Enroll 为用户生成新的 TOTP 密钥，account 是显示在认证器应用中的账号名称，例如用户名或邮箱.
+// 已完成绑定的用户需要先调用 Disable 才能重新绑定，未完成的绑定会被覆盖.
+func (m *Manager) Enroll(ctx context.Context, userID, account string) (*Enrollment, error) {
+	existing, err := m.store.Get(ctx, userID)
+	if err != nil && !errors.Is(err, ErrNotEnrolled) {
+		return nil, err
+	}
+	if existing != nil && existing.Enabled {
+		return nil, ErrAlreadyEnabled
+	}
+
+	secret, err := generateSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := m.store.Save(ctx, &model.UserMFAM{UserID: userID, Secret: secret}); err != nil {
+		return nil, err
+	}
+
+	return &Enrollment{Secret: secret, URI: keyURI(m.issuer, account, secret, m.period, m.digits)}, nil
+}
+
+// Confirm 使用认证器应用生成的验证码完成绑定，返回恢复码明文.
+// 恢复码只在此时返回一次，需要提示用户妥善保存.
+func (m *Manager) Confirm(ctx context.Context, userID, code string) ([]string, error) {
+	mfa, err := m.store.Get(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if mfa.Enabled {
+		return nil, ErrAlreadyEnabled
+	}
+
+	step, err := m.verifyCode(ctx, mfa, code)
+	if err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := generateRecoveryCodes(m.recoveryCodeCount)
+	if err != nil {
+		return nil, err
+	}
+
+	mfa.Enabled = true
+	mfa.LastStep = step
+	mfa.RecoveryCodes = hashes
+	if err := m.store.Save(ctx, mfa); err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+// Verify 校验用户提交的 TOTP 验证码.
+func (m *Manager) Verify(ctx context.Context, userID, code string) error {
+	mfa, err := m.enabled(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	_, err = m.verifyCode(ctx, mfa, code)
+	return err
+}
+
+// VerifyRecoveryCode 校验并消耗一个恢复码，用于用户无法使用认证器应用的情况.
+func (m *Manager) VerifyRecoveryCode(ctx context.Context, userID, code string) error {
+	mfa, err := m.enabled(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if locked(mfa) {
+		return ErrTooManyAttempts
+	}
+
+	ok, err := m.store.ConsumeRecoveryCode(ctx, userID, hashRecoveryCode(code))
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return m.recordFailure(ctx, userID)
+	}
+
+	return m.resetFailures(ctx, mfa)
+}
+
+// RegenerateRecoveryCodes 生成一组新的恢复码，之前的恢复码全部失效.
+func (m *Manager) RegenerateRecoveryCodes(ctx context.Context, userID string) ([]string, error) {
+	mfa, err := m.enabled(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := generateRecoveryCodes(m.recoveryCodeCount)
+	if err != nil {
+		return nil, err
+	}
+
+	mfa.RecoveryCodes = hashes
+	if err := m.store.Save(ctx, mfa); err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+// Disable 解除用户的绑定，删除密钥和恢复码.
+func (m *Manager) Disable(ctx context.Context, userID string) error {
+	return m.store.Delete(ctx, userID)
+}
+
+// Enabled 返回用户是否已完成绑定.
+func (m *Manager) Enabled(ctx context.Context, userID string) (bool, error) {
+	mfa, err := m.store.Get(ctx, userID)
+	if errors.Is(err, ErrNotEnrolled) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return mfa.Enabled, nil
+}
+
+// enabled 返回已完成绑定的用户的绑定信息.
+func (m *Manager) enabled(ctx context.Context, userID string) (*model.UserMFAM, error) {
+	mfa, err := m.store.Get(ctx, userID)
+	if errors.Is(err, ErrNotEnrolled) {
+		return nil, ErrNotEnabled
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !mfa.Enabled {
+		return nil, ErrNotEnabled
+	}
+	return mfa, nil
+}
+
+// verifyCode 校验验证码并记录使用的时间步，同一时间步及更早的验证码不能再次使用.
+// 用户被锁定时不校验验证码，校验失败时增加失败次数.
+func (m *Manager) verifyCode(ctx context.Context, mfa *model.UserMFAM, code string) (int64, error) {
+	if locked(mfa) {
+		return 0, ErrTooManyAttempts
+	}
+
+	step, ok := validateTOTP(mfa.Secret, code, time.Now(), m.period, m.digits, m.skew)
+	if !ok {
+		return 0, m.recordFailure(ctx, mfa.UserID)
+	}
+
+	advanced, err := m.store.AdvanceStep(ctx, mfa.UserID, step)
+	if err != nil {
+		return 0, err
+	}
+	if !advanced {
+		return 0, ErrCodeReused
+	}
+
+	if err := m.resetFailures(ctx, mfa); err != nil {
+		return 0, err
+	}
+	return step, nil
+}
+
+// recordFailure 记录一次验证失败，达到上限时返回 ErrTooManyAttempts，否则返回 ErrInvalidCode.
+func (m *Manager) recordFailure(ctx context.Context, userID string) error {
+	lockedOut, err := m.store.RecordFailure(ctx, userID, m.maxAttempts, m.lockout)
+	if err != nil {
+		return err
+	}
+	if lockedOut {
+		return ErrTooManyAttempts
+	}
+	return ErrInvalidCode
+}
+
+// resetFailures 在验证成功后清零失败次数，没有失败记录时不访问存储.
+func (m *Manager) resetFailures(ctx context.Context, mfa *model.UserMFAM) error {
+	if mfa.FailedAttempts == 0 && mfa.LockedUntil == nil {
+		return nil
+	}
+	if err := m.store.ResetFailures(ctx, mfa.UserID); err != nil {
+		return err
+	}
+	mfa.FailedAttempts = 0
+	mfa.LockedUntil = nil
+	return nil
+}
+
+// locked 判断用户当前是否处于锁定期.
+func locked(mfa *model.UserMFAM) bool {
+	return mfa.LockedUntil != nil && time.Now().Before(*mfa.LockedUntil)
+}
+
+// Verified 判断 token claims 是否标记了已完成多因素认证，claims 通常来自 contextx.Claims.
+func Verified(claims any) bool {
+	mapClaims, ok := claims.(jwt.MapClaims)
+	if !ok {
+		return false
+	}
+	verified, _ := mapClaims[ClaimMFA].(bool)
+	return verified
+}
@@ -0,0 +1,123 @@
+package mfa
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+)
+
+// testStores 返回需要测试的各个 Store 实现.
+func testStores(t *testing.T) map[string]Store {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "mfa.db")), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	gormStore, err := NewGormStore(db)
+	if err != nil {
+		t.Fatalf("NewGormStore: %v", err)
+	}
+
+	return map[string]Store{"memory": NewMemoryStore(), "gorm": gormStore}
+}
+
+// codeAt 返回 secret 在时间 t 对应的验证码.
+func codeAt(t *testing.T, secret string, at time.Time) string {
+	t.Helper()
+
+	key, err := secretEncoding.DecodeString(strings.ToUpper(secret))
+	if err != nil {
+		t.Fatalf("decode secret: %v", err)
+	}
+	return hotp(key, uint64(timeStep(at, defaultPeriod)), defaultDigits)
+}
+
+// enroll 完成用户的绑定，使用上一个时间步的验证码确认，当前时间步的验证码仍然可用.
+func enroll(t *testing.T, m *Manager, userID string) (string, []string) {
+	t.Helper()
+
+	ctx := context.Background()
+	enrollment, err := m.Enroll(ctx, userID, userID)
+	if err != nil {
+		t.Fatalf("Enroll: %v", err)
+	}
+	codes, err := m.Confirm(ctx, userID, codeAt(t, enrollment.Secret, time.Now().Add(-defaultPeriod)))
+	if err != nil {
+		t.Fatalf("Confirm: %v", err)
+	}
+	return enrollment.Secret, codes
+}
+
+func TestVerifyLockout(t *testing.T) {
+	for name, store := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			m := NewManager(store, WithMaxAttempts(3), WithLockout(time.Hour))
+			secret, codes := enroll(t, m, "user-1")
+
+			for i := range 2 {
+				if err := m.Verify(ctx, "user-1", "000000x"); !errors.Is(err, ErrInvalidCode) {
+					t.Fatalf("attempt %d: want ErrInvalidCode, got %v", i, err)
+				}
+			}
+			if err := m.VerifyRecoveryCode(ctx, "user-1", "wrong-code"); !errors.Is(err, ErrTooManyAttempts) {
+				t.Fatalf("third failure: want ErrTooManyAttempts, got %v", err)
+			}
+
+			// 锁定期间正确的验证码和恢复码也会被拒绝
+			if err := m.Verify(ctx, "user-1", codeAt(t, secret, time.Now())); !errors.Is(err, ErrTooManyAttempts) {
+				t.Fatalf("locked Verify: want ErrTooManyAttempts, got %v", err)
+			}
+			if err := m.VerifyRecoveryCode(ctx, "user-1", codes[0]); !errors.Is(err, ErrTooManyAttempts) {
+				t.Fatalf("locked VerifyRecoveryCode: want ErrTooManyAttempts, got %v", err)
+			}
+		})
+	}
+}
+
+func TestVerifyResetsFailures(t *testing.T) {
+	for name, store := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			m := NewManager(store, WithMaxAttempts(2), WithLockout(time.Millisecond))
+			secret, codes := enroll(t, m, "user-1")
+
+			if err := m.Verify(ctx, "user-1", "bad"); !errors.Is(err, ErrInvalidCode) {
+				t.Fatalf("want ErrInvalidCode, got %v", err)
+			}
+			if err := m.Verify(ctx, "user-1", codeAt(t, secret, time.Now())); err != nil {
+				t.Fatalf("Verify: %v", err)
+			}
+			mfa, err := store.Get(ctx, "user-1")
+			if err != nil {
+				t.Fatalf("Get: %v", err)
+			}
+			if mfa.FailedAttempts != 0 || mfa.LockedUntil != nil {
+				t.Fatalf("failures not reset: attempts=%d lockedUntil=%v", mfa.FailedAttempts, mfa.LockedUntil)
+			}
+
+			// 锁定到期后可以再次验证，成功后解除锁定
+			for range 2 {
+				_ = m.VerifyRecoveryCode(ctx, "user-1", "wrong-code")
+			}
+			time.Sleep(10 * time.Millisecond)
+			if err := m.VerifyRecoveryCode(ctx, "user-1", codes[0]); err != nil {
+				t.Fatalf("VerifyRecoveryCode after lockout: %v", err)
+			}
+			mfa, err = store.Get(ctx, "user-1")
+			if err != nil {
+				t.Fatalf("Get: %v", err)
+			}
+			if mfa.LockedUntil != nil {
+				t.Fatalf("lock not cleared: %v", mfa.LockedUntil)
+			}
+		})
+	}
+}
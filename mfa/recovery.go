@@ -0,0 +1,56 @@
+/**FileHeader
+ * @Author: Liangkang Zhang
+ * @Date: 2026/2/23 20:41:36
+ * @LastEditors: Liangkang Zhang
+ * @LastEditTime: 2026/2/23 20:41:36
+ * @Description:
+ * @Copyright: Copyright (©)}) 2026 Liangkang Zhang<lkzhang98@gmail.com>. All rights reserved. Use of this source code is governed by a MIT style license that can be found in the LICENSE file.. All rights reserved.
+ * @Email: lkzhang98@gmail.com
+ * @Repository: https://github.com/geminik12/autostack
+ */
+package mfa
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/hex"
+	"strings"
+)
+
+// recoveryCodeBytes 是每个恢复码的随机字节数，编码后为 10 个字符.
+const recoveryCodeBytes = 6
+
+// recoveryEncoding 是恢复码使用的小写 base32 编码，不包含容易混淆的 0、1、8、9.
+var recoveryEncoding = base32.NewEncoding("abcdefghijklmnopqrstuvwxyz234567").WithPadding(base32.NoPadding)
+
+// generateRecoveryCodes 生成 n 个恢复码，返回明文和逗号分隔的哈希列表.
+// 恢复码的格式为 "xxxxx-xxxxx"，明文只在生成时返回给用户.
+func generateRecoveryCodes(n int) ([]string, string, error) {
+	codes := make([]string, n)
+	hashes := make([]string, n)
+	for i := range n {
+		b := make([]byte, recoveryCodeBytes)
+		if _, err := rand.Read(b); err != nil {
+			return nil, "", err
+		}
+		s := recoveryEncoding.EncodeToString(b)
+		codes[i] = s[:len(s)/2] + "-" + s[len(s)/2:]
+		hashes[i] = hashRecoveryCode(codes[i])
+	}
+
+	return codes, strings.Join(hashes, ","), nil
+}
+
+// hashRecoveryCode 返回恢复码的 SHA-256 哈希值，忽略大小写、空格和连字符.
+func hashRecoveryCode(code string) string {
+	normalized := strings.Map(func(r rune) rune {
+		if r == '-' || r == ' ' {
+			return -1
+		}
+		return r
+	}, strings.ToLower(code))
+
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,268 @@
+/**FileHeader
+ * @Author: Liangkang Zhang
+ * @Date: 2026/2/23 20:58:13
+ * @LastEditors: Liangkang Zhang
+ * @LastEditTime: 2026/2/23 20:58:13
+ * @Description:
+ * @Copyright: Copyright (©)}) 2026 Liangkang Zhang<lkzhang98@gmail.com>. All rights reserved. Use of this source code is governed by a MIT style license that can be found in the LICENSE file.. All rights reserved.
+ * @Email: lkzhang98@gmail.com
+ * @Repository: https://github.com/geminik12/autostack
+ */
+package mfa
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/geminik12/autostack/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Store 定义了用户 TOTP 绑定信息的存储接口.
+type Store interface {
+	// Get 返回用户的绑定信息，用户没有绑定时返回 ErrNotEnrolled.
+	Get(ctx context.Context, userID string) (*model.UserMFAM, error)
+	// Save 保存用户的绑定信息，已存在时覆盖，但保留验证失败次数和锁定时间.
+	Save(ctx context.Context, mfa *model.UserMFAM) error
+	// Delete 删除用户的绑定信息.
+	Delete(ctx context.Context, userID string) error
+	// AdvanceStep 在 step 大于最后一次使用的时间步时更新并返回 true，用于防止同一个验证码被重复使用.
+	AdvanceStep(ctx context.Context, userID string, step int64) (bool, error)
+	// ConsumeRecoveryCode 删除一个未使用的恢复码哈希，恢复码不存在或已使用时返回 false.
+	ConsumeRecoveryCode(ctx context.Context, userID string, hash string) (bool, error)
+	// RecordFailure 原子地增加连续验证失败次数，达到 maxAttempts 时将用户锁定 lockout 并清零失败次数，
+	// 返回本次是否触发了锁定.
+	RecordFailure(ctx context.Context, userID string, maxAttempts int, lockout time.Duration) (bool, error)
+	// ResetFailures 在验证成功后清零连续验证失败次数并解除锁定.
+	ResetFailures(ctx context.Context, userID string) error
+}
+
+// MemoryStore 是基于内存的 TOTP 绑定信息存储，适用于单实例部署和测试.
+type MemoryStore struct {
+	mu    sync.Mutex
+	users map[string]model.UserMFAM
+}
+
+var _ Store = (*MemoryStore)(nil)
+
+// NewMemoryStore 创建一个基于内存的 TOTP 绑定信息存储.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{users: make(map[string]model.UserMFAM)}
+}
+
+// Get 返回用户的绑定信息.
+func (s *MemoryStore) Get(ctx context.Context, userID string) (*model.UserMFAM, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	mfa, ok := s.users[userID]
+	if !ok {
+		return nil, ErrNotEnrolled
+	}
+	return &mfa, nil
+}
+
+// Save 保存用户的绑定信息.
+func (s *MemoryStore) Save(ctx context.Context, mfa *model.UserMFAM) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	saved := *mfa
+	if existing, ok := s.users[mfa.UserID]; ok {
+		saved.FailedAttempts = existing.FailedAttempts
+		saved.LockedUntil = existing.LockedUntil
+	}
+	s.users[mfa.UserID] = saved
+	return nil
+}
+
+// Delete 删除用户的绑定信息.
+func (s *MemoryStore) Delete(ctx context.Context, userID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.users, userID)
+	return nil
+}
+
+// AdvanceStep 更新最后一次使用的时间步.
+func (s *MemoryStore) AdvanceStep(ctx context.Context, userID string, step int64) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	mfa, ok := s.users[userID]
+	if !ok {
+		return false, ErrNotEnrolled
+	}
+	if step <= mfa.LastStep {
+		return false, nil
+	}
+	mfa.LastStep = step
+	s.users[userID] = mfa
+	return true, nil
+}
+
+// ConsumeRecoveryCode 删除一个未使用的恢复码哈希.
+func (s *MemoryStore) ConsumeRecoveryCode(ctx context.Context, userID string, hash string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	mfa, ok := s.users[userID]
+	if !ok {
+		return false, ErrNotEnrolled
+	}
+	remaining, ok := removeCode(mfa.RecoveryCodes, hash)
+	if !ok {
+		return false, nil
+	}
+	mfa.RecoveryCodes = remaining
+	s.users[userID] = mfa
+	return true, nil
+}
+
+// RecordFailure 增加连续验证失败次数，达到上限时锁定用户.
+func (s *MemoryStore) RecordFailure(ctx context.Context, userID string, maxAttempts int, lockout time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	mfa, ok := s.users[userID]
+	if !ok {
+		return false, ErrNotEnrolled
+	}
+	mfa.FailedAttempts++
+	locked := mfa.FailedAttempts >= maxAttempts
+	if locked {
+		until := time.Now().Add(lockout)
+		mfa.FailedAttempts = 0
+		mfa.LockedUntil = &until
+	}
+	s.users[userID] = mfa
+	return locked, nil
+}
+
+// ResetFailures 清零连续验证失败次数并解除锁定.
+func (s *MemoryStore) ResetFailures(ctx context.Context, userID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	mfa, ok := s.users[userID]
+	if !ok {
+		return nil
+	}
+	mfa.FailedAttempts = 0
+	mfa.LockedUntil = nil
+	s.users[userID] = mfa
+	return nil
+}
+
+// GormStore 是基于 gorm 的 TOTP 绑定信息存储，绑定信息保存在 user_mfa 表.
+type GormStore struct {
+	db *gorm.DB
+}
+
+var _ Store = (*GormStore)(nil)
+
+// NewGormStore 创建一个基于 gorm 的 TOTP 绑定信息存储，user_mfa 表不存在时会自动创建.
+func NewGormStore(db *gorm.DB) (*GormStore, error) {
+	if err := db.AutoMigrate(&model.UserMFAM{}); err != nil {
+		return nil, err
+	}
+
+	return &GormStore{db: db}, nil
+}
+
+// Get 返回用户的绑定信息.
+func (s *GormStore) Get(ctx context.Context, userID string) (*model.UserMFAM, error) {
+	var mfa model.UserMFAM
+	if err := s.db.WithContext(ctx).Where("userID = ?", userID).First(&mfa).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotEnrolled
+		}
+		return nil, err
+	}
+	return &mfa, nil
+}
+
+// Save 保存用户的绑定信息，更新已有记录时不覆盖验证失败次数和锁定时间.
+func (s *GormStore) Save(ctx context.Context, mfa *model.UserMFAM) error {
+	return s.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "userID"}},
+		DoUpdates: clause.AssignmentColumns([]string{"secret", "enabled", "lastStep", "recoveryCodes", "updatedAt"}),
+	}).Create(mfa).Error
+}
+
+// Delete 删除用户的绑定信息.
+func (s *GormStore) Delete(ctx context.Context, userID string) error {
+	return s.db.WithContext(ctx).Where("userID = ?", userID).Delete(&model.UserMFAM{}).Error
+}
+
+// AdvanceStep 使用条件更新保证并发请求中同一个时间步只有一个能够成功.
+func (s *GormStore) AdvanceStep(ctx context.Context, userID string, step int64) (bool, error) {
+	result := s.db.WithContext(ctx).Model(&model.UserMFAM{}).
+		Where("userID = ? AND lastStep < ?", userID, step).Update("lastStep", step)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// ConsumeRecoveryCode 使用乐观锁删除恢复码，恢复码列表被并发修改时返回 false.
+func (s *GormStore) ConsumeRecoveryCode(ctx context.Context, userID string, hash string) (bool, error) {
+	mfa, err := s.Get(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+
+	remaining, ok := removeCode(mfa.RecoveryCodes, hash)
+	if !ok {
+		return false, nil
+	}
+
+	result := s.db.WithContext(ctx).Model(&model.UserMFAM{}).
+		Where("userID = ? AND recoveryCodes = ?", userID, mfa.RecoveryCodes).Update("recoveryCodes", remaining)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// RecordFailure 使用原子自增记录失败次数，并发请求中只有一个能够触发锁定.
+func (s *GormStore) RecordFailure(ctx context.Context, userID string, maxAttempts int, lockout time.Duration) (bool, error) {
+	result := s.db.WithContext(ctx).Model(&model.UserMFAM{}).
+		Where("userID = ?", userID).Update("failedAttempts", gorm.Expr("failedAttempts + 1"))
+	if result.Error != nil {
+		return false, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return false, ErrNotEnrolled
+	}
+
+	result = s.db.WithContext(ctx).Model(&model.UserMFAM{}).
+		Where("userID = ? AND failedAttempts >= ?", userID, maxAttempts).
+		Updates(map[string]any{"failedAttempts": 0, "lockedUntil": time.Now().Add(lockout)})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// ResetFailures 清零连续验证失败次数并解除锁定.
+func (s *GormStore) ResetFailures(ctx context.Context, userID string) error {
+	return s.db.WithContext(ctx).Model(&model.UserMFAM{}).Where("userID = ?", userID).
+		Updates(map[string]any{"failedAttempts": 0, "lockedUntil": nil}).Error
+}
+
+// removeCode 从逗号分隔的恢复码哈希列表中删除 hash.
+func removeCode(codes, hash string) (string, bool) {
+	list := strings.Split(codes, ",")
+	i := slices.Index(list, hash)
+	if codes == "" || i < 0 {
+		return codes, false
+	}
+	return strings.Join(slices.Delete(list, i, i+1), ","), true
+}
@@ -0,0 +1,108 @@
+/**FileHeader
+ * @Author: Liangkang Zhang
+ * @Date: 2026/2/23 20:26:51
+ * @LastEditors: Liangkang Zhang
+ * @LastEditTime: 2026/2/23 20:26:51
+ * @Description:
+ * @Copyright: Copyright (©)}) 2026 Liangkang Zhang<lkzhang98@gmail.com>. All rights reserved. Use of this source code is governed by a MIT style license that can be found in the LICENSE file.. All rights reserved.
+ * @Email: lkzhang98@gmail.com
+ * @Repository: https://github.com/geminik12/autostack
+ */
+package mfa
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// secretBytes 是 TOTP 密钥的字节数，RFC 4226 推荐至少 160 位.
+const secretBytes = 20
+
+// secretEncoding 是 TOTP 密钥使用的无填充 base32 编码，与认证器应用的约定一致.
+var secretEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// generateSecret 生成一个随机的 TOTP 密钥.
+func generateSecret() (string, error) {
+	b := make([]byte, secretBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return secretEncoding.EncodeToString(b), nil
+}
+
+// hotp 按照 RFC 4226 计算计数器 counter 对应的 digits 位验证码.
+func hotp(key []byte, counter uint64, digits int) string {
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], counter)
+
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+
+	// 动态截断
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	mod := uint32(1)
+	for range digits {
+		mod *= 10
+	}
+
+	return fmt.Sprintf("%0*d", digits, value%mod)
+}
+
+// timeStep 返回时间 t 对应的 TOTP 时间步.
+func timeStep(t time.Time, period time.Duration) int64 {
+	return t.Unix() / int64(period/time.Second)
+}
+
+// validateTOTP 按照 RFC 6238 校验验证码，允许前后 skew 个时间步的时钟偏差.
+// 校验通过时返回匹配的时间步.
+func validateTOTP(secret, code string, t time.Time, period time.Duration, digits, skew int) (int64, bool) {
+	key, err := secretEncoding.DecodeString(strings.ToUpper(secret))
+	if err != nil || len(code) != digits {
+		return 0, false
+	}
+
+	current := timeStep(t, period)
+	for i := -skew; i <= skew; i++ {
+		step := current + int64(i)
+		if step < 0 {
+			continue
+		}
+		if subtle.ConstantTimeCompare([]byte(hotp(key, uint64(step), digits)), []byte(code)) == 1 {
+			return step, true
+		}
+	}
+
+	return 0, false
+}
+
+// keyURI 返回认证器应用使用的 otpauth URI，通常编码为二维码展示给用户.
+// 格式见 https://github.com/google/google-authenticator/wiki/Key-Uri-Format.
+func keyURI(issuer, account, secret string, period time.Duration, digits int) string {
+	label := account
+	if issuer != "" {
+		label = issuer + ":" + account
+	}
+
+	query := url.Values{}
+	query.Set("secret", secret)
+	if issuer != "" {
+		query.Set("issuer", issuer)
+	}
+	query.Set("algorithm", "SHA1")
+	query.Set("digits", strconv.Itoa(digits))
+	query.Set("period", strconv.Itoa(int(period/time.Second)))
+
+	return (&url.URL{Scheme: "otpauth", Host: "totp", Path: "/" + label, RawQuery: query.Encode()}).String()
+}
@@ -0,0 +1,105 @@
+/**FileHeader
+ * @Author: Liangkang Zhang
+ * @Date: 2026/2/20 21:16:25
+ * @LastEditors: Liangkang Zhang
+ * @LastEditTime: 2026/2/20 21:16:25
+ * @Description:
+ * @Copyright: Copyright (©)}) 2026 Liangkang Zhang<lkzhang98@gmail.com>. All rights reserved. Use of this source code is governed by a MIT style license that can be found in the LICENSE file.. All rights reserved.
+ * @Email: lkzhang98@gmail.com
+ * @Repository: https://github.com/geminik12/autostack
+ */
+package gin
+
+import (
+	"slices"
+
+	"github.com/geminik12/autostack/apikey"
+	"github.com/geminik12/autostack/contextx"
+	"github.com/geminik12/autostack/core"
+	"github.com/geminik12/autostack/errorsx"
+	"github.com/geminik12/autostack/known"
+	"github.com/geminik12/autostack/log"
+	"github.com/gin-gonic/gin"
+)
+
+// APIKeyOption 用于配置 API Key 认证中间件.
+type APIKeyOption func(*apiKeyOptions)
+
+// apiKeyOptions 是 API Key 认证中间件的配置.
+type apiKeyOptions struct {
+	header   string
+	fallback gin.HandlerFunc
+}
+
+// WithAPIKeyHeader 设置携带 API Key 的 Header，默认为 X-API-Key.
+func WithAPIKeyHeader(header string) APIKeyOption {
+	return func(o *apiKeyOptions) {
+		if header != "" {
+			o.header = header
+		}
+	}
+}
+
+// WithAPIKeyFallback 设置请求没有携带 API Key 时使用的认证中间件，例如 AuthnMiddleware，
+// 从而同时支持 API Key 和 JWT 两种认证方式. 未设置时没有携带 API Key 的请求认证失败.
+func WithAPIKeyFallback(fallback gin.HandlerFunc) APIKeyOption {
+	return func(o *apiKeyOptions) {
+		o.fallback = fallback
+	}
+}
+
+// APIKeyMiddleware 是一个使用 API Key 进行认证的中间件，适用于不方便签发 JWT 的机器客户端和 CI 任务.
+// 认证成功后与 AuthnMiddleware 一样在上下文中存放用户 ID 和用户名，并通过 contextx.WithScopes 存放 API Key 的授权范围.
+func APIKeyMiddleware(manager *apikey.Manager, retriever UserRetriever, opts ...APIKeyOption) gin.HandlerFunc {
+	o := &apiKeyOptions{header: known.XAPIKey}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return func(c *gin.Context) {
+		key := c.GetHeader(o.header)
+		if key == "" && o.fallback != nil {
+			o.fallback(c)
+			return
+		}
+
+		record, err := manager.Verify(c.Request.Context(), key)
+		if err != nil {
+			core.WriteResponse(c, nil, errorsx.ErrUnauthenticated.WithMessage("%s", err.Error()))
+			c.Abort()
+			return
+		}
+
+		log.Debugw("API key verification successful", "userID", record.UserID, "prefix", record.Prefix)
+
+		user, err := retriever.GetUser(c, record.UserID)
+		if err != nil {
+			core.WriteResponse(c, nil, errorsx.ErrUnauthenticated.WithMessage("%s", err.Error()))
+			c.Abort()
+			return
+		}
+
+		ctx := contextx.WithUserID(c.Request.Context(), user.UserID)
+		ctx = contextx.WithUsername(ctx, user.Username)
+		ctx = contextx.WithScopes(ctx, apikey.Scopes(record))
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+	}
+}
+
+// RequireScopes 是一个要求凭证拥有全部 scopes 的中间件，需要放在 APIKeyMiddleware 之后.
+func RequireScopes(scopes ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		granted := contextx.Scopes(c.Request.Context())
+		for _, scope := range scopes {
+			if !slices.Contains(granted, scope) {
+				core.WriteResponse(c, nil, errorsx.ErrPermissionDenied.WithMessage("missing scope: %s", scope))
+				c.Abort()
+				return
+			}
+		}
+
+		c.Next()
+	}
+}
@@ -11,28 +11,35 @@
 package gin
 
 import (
-	"context"
-
 	"github.com/geminik12/autostack/contextx"
 	"github.com/geminik12/autostack/core"
 	"github.com/geminik12/autostack/errorsx"
 	"github.com/geminik12/autostack/log"
-	"github.com/geminik12/autostack/model"
+	"github.com/geminik12/autostack/middleware"
 	"github.com/geminik12/autostack/token"
 	"github.com/gin-gonic/gin"
 )
 
-// UserRetriever 用于根据用户名获取用户的接口.
-type UserRetriever interface {
-	// GetUser 根据用户ID获取用户信息
-	GetUser(ctx context.Context, userID string) (*model.UserM, error)
-}
+// UserRetriever 用于根据用户名获取用户的接口，与 gRPC 认证拦截器使用同一个接口.
+type UserRetriever = middleware.UserRetriever
 
 // AuthnMiddleware 是一个认证中间件，用于从 gin.Context 中提取 token 并验证 token 是否合法.
+// 使用 token 包的默认 Manager 解析 token.
 func AuthnMiddleware(retriever UserRetriever) gin.HandlerFunc {
+	return AuthnMiddlewareWithManager(nil, retriever)
+}
+
+// AuthnMiddlewareWithManager 与 AuthnMiddleware 相同，但使用指定的 token.Manager 解析 token.
+// manager 为 nil 时，每次请求都使用 token 包当前的默认 Manager.
+func AuthnMiddlewareWithManager(manager *token.Manager, retriever UserRetriever) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		m := manager
+		if m == nil {
+			m = token.Default()
+		}
+
 		// 解析 JWT Token
-		userID, err := token.ParseRequest(c)
+		userID, claims, err := m.ParseRequestWithClaims(c)
 		if err != nil {
 			core.WriteResponse(c, nil, errorsx.ErrTokenInvalid.WithMessage("%s", err.Error()))
 			c.Abort()
@@ -50,6 +57,10 @@ func AuthnMiddleware(retriever UserRetriever) gin.HandlerFunc {
 
 		ctx := contextx.WithUserID(c.Request.Context(), user.UserID)
 		ctx = contextx.WithUsername(ctx, user.Username)
+		// 存放 token claims，处理函数可以通过 token.ClaimsFromContext 读取强类型 claims
+		if claims != nil {
+			ctx = contextx.WithClaims(ctx, claims)
+		}
 		c.Request = c.Request.WithContext(ctx)
 
 		c.Next()
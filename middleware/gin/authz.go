@@ -15,26 +15,68 @@ import (
 	"github.com/geminik12/autostack/core"
 	"github.com/geminik12/autostack/errorsx"
 	"github.com/geminik12/autostack/log"
+	"github.com/geminik12/autostack/middleware"
 	"github.com/gin-gonic/gin"
 )
 
-// Authorizer 用于定义授权接口的实现.
-type Authorizer interface {
-	Authorize(subject, object, action string) (bool, error)
+// Authorizer 用于定义授权接口的实现，与 gRPC 授权拦截器使用同一个接口.
+type Authorizer = middleware.Authorizer
+
+// ContextAuthorizer 是可以接收请求上下文的授权接口.
+// Authorizer 同时实现该接口时，中间件会传入请求上下文，例如用于在审计记录中关联请求 ID.
+type ContextAuthorizer = middleware.ContextAuthorizer
+
+// PolicyMatcher 用于判断是否存在可以匹配指定对象和动作的策略.
+type PolicyMatcher interface {
+	HasMatchingPolicy(object, action string) (bool, error)
+}
+
+// AuthzOption 用于配置 Gin 授权中间件.
+type AuthzOption func(*authzOptions)
+
+// authzOptions 是 Gin 授权中间件的配置.
+type authzOptions struct {
+	routeTemplate bool
+}
+
+// WithRouteTemplate 使用路由模板（c.FullPath()，例如 /v1/users/:userID）代替请求路径作为授权对象，
+// 策略可以直接使用路由模板，无需通配符或为每个 ID 单独配置. 请求没有匹配任何路由时仍使用请求路径.
+func WithRouteTemplate() AuthzOption {
+	return func(o *authzOptions) {
+		o.routeTemplate = true
+	}
 }
 
 // AuthzMiddleware 是一个 Gin 中间件，用于进行请求授权.
-func AuthzMiddleware(authorizer Authorizer) gin.HandlerFunc {
+func AuthzMiddleware(authorizer Authorizer, opts ...AuthzOption) gin.HandlerFunc {
+	o := &authzOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
 	return func(c *gin.Context) {
 		subject := contextx.UserID(c.Request.Context())
 		object := c.Request.URL.Path
 		action := c.Request.Method
 
+		if fullPath := c.FullPath(); o.routeTemplate && fullPath != "" {
+			object = fullPath
+		}
+
+		// 将路由参数存放到上下文中，供 ABAC 规则使用
+		if len(c.Params) > 0 {
+			params := make(map[string]string, len(c.Params))
+			for _, p := range c.Params {
+				params[p.Key] = p.Value
+			}
+			c.Request = c.Request.WithContext(contextx.WithParams(c.Request.Context(), params))
+		}
+
 		// 记录授权上下文信息
 		log.Debugw("Build authorize context", "subject", subject, "object", object, "action", action)
 
 		// 调用授权接口进行验证
-		if allowed, err := authorizer.Authorize(subject, object, action); err != nil || !allowed {
+		if allowed, err := middleware.Authorize(c.Request.Context(), authorizer, subject, object, action); err != nil || !allowed {
 			core.WriteResponse(c, nil, errorsx.ErrPermissionDenied.WithMessage(
 				"access denied: subject=%s, object=%s, action=%s, reason=%v",
 				subject,
@@ -49,3 +91,20 @@ func AuthzMiddleware(authorizer Authorizer) gin.HandlerFunc {
 		c.Next() // 继续处理请求
 	}
 }
+
+// UnmatchedRoutes 返回 engine 中注册的、没有任何策略可以匹配的路由，用于发现遗漏配置策略的接口.
+// 路由的授权对象为路由模板，与使用 WithRouteTemplate 时的授权对象一致，matcher 通常为 *authz.Authz.
+func UnmatchedRoutes(engine *gin.Engine, matcher PolicyMatcher) ([]gin.RouteInfo, error) {
+	var unmatched []gin.RouteInfo
+	for _, route := range engine.Routes() {
+		ok, err := matcher.HasMatchingPolicy(route.Path, route.Method)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			unmatched = append(unmatched, route)
+		}
+	}
+
+	return unmatched, nil
+}
@@ -0,0 +1,37 @@
+/**FileHeader
+ * @Author: Liangkang Zhang
+ * @Date: 2026/2/23 22:05:48
+ * @LastEditors: Liangkang Zhang
+ * @LastEditTime: 2026/2/23 22:05:48
+ * @Description:
+ * @Copyright: Copyright (©)}) 2026 Liangkang Zhang<lkzhang98@gmail.com>. All rights reserved. Use of this source code is governed by a MIT style license that can be found in the LICENSE file.. All rights reserved.
+ * @Email: lkzhang98@gmail.com
+ * @Repository: https://github.com/geminik12/autostack
+ */
+package gin
+
+import (
+	"github.com/geminik12/autostack/contextx"
+	"github.com/geminik12/autostack/core"
+	"github.com/geminik12/autostack/errorsx"
+	"github.com/geminik12/autostack/mfa"
+	"github.com/gin-gonic/gin"
+)
+
+// RequireMFA 是一个要求会话已完成多因素认证的中间件，需要放在 AuthnMiddleware 之后，
+// 通常用于管理员（known.RoleAdmin）使用的路由分组：
+//
+//	admin := engine.Group("/v1/admin", AuthnMiddleware(retriever), RequireMFA())
+//
+// token 的 claims 中没有 mfa.ClaimMFA 标记时拒绝访问，客户端需要先通过 mfa.VerifyHandler 换取带有标记的 token.
+func RequireMFA() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !mfa.Verified(contextx.Claims(c.Request.Context())) {
+			core.WriteResponse(c, nil, errorsx.ErrPermissionDenied.WithMessage("multi-factor authentication required"))
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,103 @@
+/**FileHeader
+ * @Author: Liangkang Zhang
+ * @Date: 2026/2/14 21:26:05
+ * @LastEditors: Liangkang Zhang
+ * @LastEditTime: 2026/2/14 21:26:05
+ * @Description:
+ * @Copyright: Copyright (©)}) 2026 Liangkang Zhang<lkzhang98@gmail.com>. All rights reserved. Use of this source code is governed by a MIT style license that can be found in the LICENSE file.. All rights reserved.
+ * @Email: lkzhang98@gmail.com
+ * @Repository: https://github.com/geminik12/autostack
+ */
+package gin
+
+import (
+	"context"
+
+	"github.com/geminik12/autostack/contextx"
+	"github.com/geminik12/autostack/core"
+	"github.com/geminik12/autostack/errorsx"
+	"github.com/geminik12/autostack/known"
+	"github.com/geminik12/autostack/log"
+	"github.com/gin-gonic/gin"
+)
+
+// TenantAuthorizer 用于定义多租户授权接口的实现.
+type TenantAuthorizer interface {
+	AuthorizeInTenant(subject, tenant, object, action string) (bool, error)
+}
+
+// ContextTenantAuthorizer 是可以接收请求上下文的多租户授权接口.
+type ContextTenantAuthorizer interface {
+	AuthorizeInTenantWithContext(ctx context.Context, subject, tenant, object, action string) (bool, error)
+}
+
+// authorizeInTenant 调用多租户授权接口，优先使用可以接收请求上下文的实现.
+func authorizeInTenant(ctx context.Context, authorizer TenantAuthorizer, subject, tenant, object, action string) (bool, error) {
+	if ca, ok := authorizer.(ContextTenantAuthorizer); ok {
+		return ca.AuthorizeInTenantWithContext(ctx, subject, tenant, object, action)
+	}
+	return authorizer.AuthorizeInTenant(subject, tenant, object, action)
+}
+
+// TenantMiddleware 是一个 Gin 中间件，用于从请求头 `x-tenant-id` 中读取租户 ID 并注入到请求上下文中.
+// 租户 ID 由客户端提供，是否有权访问该租户由 TenantAuthzMiddleware 根据租户内的角色和策略决定.
+func TenantMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if tenantID := c.Request.Header.Get(known.XTenantID); tenantID != "" {
+			ctx := contextx.WithTenantID(c.Request.Context(), tenantID)
+			c.Request = c.Request.WithContext(ctx)
+		}
+
+		c.Next()
+	}
+}
+
+// TenantAuthzMiddleware 是 AuthzMiddleware 的多租户版本，使用上下文中的租户 ID 进行授权.
+// 请求上下文中没有租户 ID 时直接拒绝访问. 支持与 AuthzMiddleware 相同的选项，例如 WithRouteTemplate.
+func TenantAuthzMiddleware(authorizer TenantAuthorizer, opts ...AuthzOption) gin.HandlerFunc {
+	o := &authzOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return func(c *gin.Context) {
+		subject := contextx.UserID(c.Request.Context())
+		tenant := contextx.TenantID(c.Request.Context())
+		object := c.Request.URL.Path
+		action := c.Request.Method
+
+		if fullPath := c.FullPath(); o.routeTemplate && fullPath != "" {
+			object = fullPath
+		}
+
+		// 记录授权上下文信息
+		log.Debugw("Build authorize context", "subject", subject, "tenant", tenant, "object", object, "action", action)
+
+		if tenant == "" {
+			core.WriteResponse(c, nil, errorsx.ErrPermissionDenied.WithMessage(
+				"access denied: subject=%s, object=%s, action=%s, reason=missing tenant",
+				subject,
+				object,
+				action,
+			))
+			c.Abort()
+			return
+		}
+
+		// 调用授权接口进行验证
+		if allowed, err := authorizeInTenant(c.Request.Context(), authorizer, subject, tenant, object, action); err != nil || !allowed {
+			core.WriteResponse(c, nil, errorsx.ErrPermissionDenied.WithMessage(
+				"access denied: subject=%s, tenant=%s, object=%s, action=%s, reason=%v",
+				subject,
+				tenant,
+				object,
+				action,
+				err,
+			))
+			c.Abort()
+			return
+		}
+
+		c.Next() // 继续处理请求
+	}
+}
@@ -0,0 +1,125 @@
+package gin
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/geminik12/autostack/authz"
+	"github.com/geminik12/autostack/contextx"
+	"github.com/geminik12/autostack/known"
+	"github.com/gin-gonic/gin"
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// newTenantTestAuthz 创建一个使用临时 SQLite 数据库和 DomainRBACModel 的授权器.
+func newTenantTestAuthz(t *testing.T) *authz.Authz {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "authz.db")), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+
+	a, err := authz.NewAuthz(db, authz.WithAclModel(authz.DomainRBACModel), authz.WithAutoLoadPolicyTime(0))
+	if err != nil {
+		t.Fatalf("NewAuthz: %v", err)
+	}
+
+	return a
+}
+
+// newTenantTestEngine 创建一个以 userID 身份访问的 Gin 引擎，路由由 TenantAuthzMiddleware 保护.
+func newTenantTestEngine(a *authz.Authz, userID string, opts ...AuthzOption) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	engine := gin.New()
+	engine.Use(func(c *gin.Context) {
+		c.Request = c.Request.WithContext(contextx.WithUserID(c.Request.Context(), userID))
+		c.Next()
+	})
+	engine.Use(TenantMiddleware(), TenantAuthzMiddleware(a, opts...))
+	engine.GET("/v1/posts/:postID", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	return engine
+}
+
+func TestTenantAuthzMiddleware(t *testing.T) {
+	a := newTenantTestAuthz(t)
+	for _, tenant := range []string{"tenant-a", "tenant-b"} {
+		if err := a.AddTenantRolePolicy("viewer", tenant, "/v1/posts/*", http.MethodGet); err != nil {
+			t.Fatalf("AddTenantRolePolicy: %v", err)
+		}
+	}
+	if err := a.GrantRoleInTenant("alice", "viewer", "tenant-a"); err != nil {
+		t.Fatalf("GrantRoleInTenant: %v", err)
+	}
+
+	engine := newTenantTestEngine(a, "alice")
+
+	tests := []struct {
+		name   string
+		tenant string
+		want   int
+	}{
+		{name: "granted tenant", tenant: "tenant-a", want: http.StatusOK},
+		{name: "other tenant", tenant: "tenant-b", want: http.StatusForbidden},
+		{name: "missing tenant", tenant: "", want: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/v1/posts/1", nil)
+			if tt.tenant != "" {
+				req.Header.Set(known.XTenantID, tt.tenant)
+			}
+
+			w := httptest.NewRecorder()
+			engine.ServeHTTP(w, req)
+
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d", w.Code, tt.want)
+			}
+		})
+	}
+}
+
+func TestTenantAuthzMiddlewareRouteTemplate(t *testing.T) {
+	a := newTenantTestAuthz(t)
+	if err := a.AddTenantRolePolicy("viewer", "tenant-a", "/v1/posts/:postID", http.MethodGet); err != nil {
+		t.Fatalf("AddTenantRolePolicy: %v", err)
+	}
+	if err := a.GrantRoleInTenant("alice", "viewer", "tenant-a"); err != nil {
+		t.Fatalf("GrantRoleInTenant: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		opts []AuthzOption
+		want int
+	}{
+		{name: "request path", want: http.StatusForbidden},
+		{name: "route template", opts: []AuthzOption{WithRouteTemplate()}, want: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/v1/posts/1", nil)
+			req.Header.Set(known.XTenantID, "tenant-a")
+
+			w := httptest.NewRecorder()
+			newTenantTestEngine(a, "alice", tt.opts...).ServeHTTP(w, req)
+
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d", w.Code, tt.want)
+			}
+		})
+	}
+}
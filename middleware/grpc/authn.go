@@ -0,0 +1,106 @@
+/**FileHeader
+ * @Author: Liangkang Zhang
+ * @Date: 2026/2/11 21:03:22
+ * @LastEditors: Liangkang Zhang
+ * @LastEditTime: 2026/2/11 21:03:22
+ * @Description:
+ * @Copyright: Copyright (©)}) 2026 Liangkang Zhang<lkzhang98@gmail.com>. All rights reserved. Use of this source code is governed by a MIT style license that can be found in the LICENSE file.. All rights reserved.
+ * @Email: lkzhang98@gmail.com
+ * @Repository: https://github.com/geminik12/autostack
+ */
+package grpc
+
+import (
+	"context"
+
+	"github.com/geminik12/autostack/contextx"
+	"github.com/geminik12/autostack/errorsx"
+	"github.com/geminik12/autostack/log"
+	"github.com/geminik12/autostack/middleware"
+	"github.com/geminik12/autostack/token"
+	"github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/auth"
+	"google.golang.org/grpc"
+)
+
+// UserRetriever 用于根据用户 ID 获取用户的接口，与 Gin 认证中间件使用同一个接口.
+type UserRetriever = middleware.UserRetriever
+
+// AuthnOption 用于配置 gRPC 认证拦截器.
+type AuthnOption func(*authnOptions)
+
+// authnOptions 是 gRPC 认证拦截器的配置.
+type authnOptions struct {
+	manager     *token.Manager
+	skipMethods map[string]struct{}
+}
+
+// WithManager 设置解析 token 使用的 token.Manager.
+// 未设置时，每次请求都使用 token 包当前的默认 Manager.
+func WithManager(manager *token.Manager) AuthnOption {
+	return func(o *authnOptions) {
+		o.manager = manager
+	}
+}
+
+// WithSkipMethods 设置跳过认证的完整方法名，例如 /pkg.Service/Method.
+// token.WithSkipPaths 中配置的路径同样会按完整方法名匹配.
+func WithSkipMethods(methods ...string) AuthnOption {
+	return func(o *authnOptions) {
+		for _, method := range methods {
+			o.skipMethods[method] = struct{}{}
+		}
+	}
+}
+
+// AuthnUnaryInterceptor 是 gRPC 一元调用的认证拦截器，从 metadata 中提取 token 并验证 token 是否合法.
+func AuthnUnaryInterceptor(retriever UserRetriever, opts ...AuthnOption) grpc.UnaryServerInterceptor {
+	return auth.UnaryServerInterceptor(authnFunc(retriever, opts...))
+}
+
+// AuthnStreamInterceptor 是 gRPC 流式调用的认证拦截器，从 metadata 中提取 token 并验证 token 是否合法.
+func AuthnStreamInterceptor(retriever UserRetriever, opts ...AuthnOption) grpc.StreamServerInterceptor {
+	return auth.StreamServerInterceptor(authnFunc(retriever, opts...))
+}
+
+// authnFunc 返回认证函数，认证成功后将用户信息存放到上下文中.
+func authnFunc(retriever UserRetriever, opts ...AuthnOption) auth.AuthFunc {
+	o := &authnOptions{skipMethods: make(map[string]struct{})}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return func(ctx context.Context) (context.Context, error) {
+		if method, ok := grpc.Method(ctx); ok {
+			if _, skip := o.skipMethods[method]; skip {
+				return ctx, nil
+			}
+		}
+
+		m := o.manager
+		if m == nil {
+			m = token.Default()
+		}
+
+		// 解析 JWT Token，token.WithSkipPaths 匹配的方法返回空的 userID
+		userID, claims, err := m.ParseRequestWithClaims(ctx)
+		if err != nil {
+			return nil, errorsx.ErrTokenInvalid.WithMessage("%s", err.Error()).GRPCStatus().Err()
+		}
+		if claims == nil {
+			return ctx, nil
+		}
+
+		log.Debugw("Token parsing successful", "userID", userID)
+
+		user, err := retriever.GetUser(ctx, userID)
+		if err != nil {
+			return nil, errorsx.ErrUnauthenticated.WithMessage("%s", err.Error()).GRPCStatus().Err()
+		}
+
+		ctx = contextx.WithUserID(ctx, user.UserID)
+		ctx = contextx.WithUsername(ctx, user.Username)
+		ctx = contextx.WithClaims(ctx, claims)
+
+		return ctx, nil
+	}
+}
@@ -0,0 +1,285 @@
+/**FileHeader
+ * @Author: Liangkang Zhang
+ * @Date: 2026/2/12 20:37:45
+ * @LastEditors: Liangkang Zhang
+ * @LastEditTime: 2026/2/12 20:37:45
+ * @Description:
+ * @Copyright: Copyright (©)}) 2026 Liangkang Zhang<lkzhang98@gmail.com>. All rights reserved. Use of this source code is governed by a MIT style license that can be found in the LICENSE file.. All rights reserved.
+ * @Email: lkzhang98@gmail.com
+ * @Repository: https://github.com/geminik12/autostack
+ */
+package grpc
+
+import (
+	"context"
+	"strings"
+
+	"github.com/geminik12/autostack/contextx"
+	"github.com/geminik12/autostack/errorsx"
+	"github.com/geminik12/autostack/log"
+	"github.com/geminik12/autostack/middleware"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// 命名约定中使用的默认动作.
+const (
+	ActionRead   = "read"
+	ActionCreate = "create"
+	ActionUpdate = "update"
+	ActionDelete = "delete"
+	ActionWrite  = "write"
+)
+
+// Authorizer 用于定义授权接口的实现，与 Gin 授权中间件使用同一个接口.
+type Authorizer = middleware.Authorizer
+
+// ContextAuthorizer 是可以接收请求上下文的授权接口，Authorizer 同时实现该接口时拦截器会传入请求上下文.
+type ContextAuthorizer = middleware.ContextAuthorizer
+
+// ParamsFunc 从请求消息中提取请求参数，提取的参数通过 contextx.WithParams 传给授权器，例如供 ABAC 规则使用.
+type ParamsFunc func(fullMethod string, req any) map[string]string
+
+// ActionResolver 根据完整的方法名（例如 /pkg.Service/Method）返回授权动作.
+type ActionResolver func(fullMethod string) string
+
+// conventionPrefixes 定义了方法名前缀到动作的映射，按顺序匹配.
+var conventionPrefixes = []struct {
+	prefix string
+	action string
+}{
+	{"Get", ActionRead},
+	{"List", ActionRead},
+	{"Watch", ActionRead},
+	{"Search", ActionRead},
+	{"Create", ActionCreate},
+	{"Update", ActionUpdate},
+	{"Patch", ActionUpdate},
+	{"Delete", ActionDelete},
+	{"Remove", ActionDelete},
+}
+
+// ConventionActionResolver 按方法名的命名约定推断动作：
+// Get*/List*/Watch*/Search* 为 read，Create* 为 create，Update*/Patch* 为 update，
+// Delete*/Remove* 为 delete，其他方法为 write.
+func ConventionActionResolver() ActionResolver {
+	return func(fullMethod string) string {
+		name := methodName(fullMethod)
+		for _, p := range conventionPrefixes {
+			if strings.HasPrefix(name, p.prefix) {
+				return p.action
+			}
+		}
+		return ActionWrite
+	}
+}
+
+// ProtoOptionActionResolver 从方法的 proto 自定义选项中读取动作，例如：
+//
+//	extend google.protobuf.MethodOptions {
+//	  string action = 50001;
+//	}
+//
+//	rpc GetUser(GetUserRequest) returns (GetUserResponse) {
+//	  option (action) = "read";
+//	}
+//
+// ext 必须是 string 类型的 MethodOptions 扩展. 方法未声明该选项或描述符未注册时，使用 fallback 推断动作，
+// fallback 为 nil 时使用 ConventionActionResolver.
+func ProtoOptionActionResolver(ext protoreflect.ExtensionType, fallback ActionResolver) ActionResolver {
+	if fallback == nil {
+		fallback = ConventionActionResolver()
+	}
+
+	return func(fullMethod string) string {
+		if action := methodOption(fullMethod, ext); action != "" {
+			return action
+		}
+		return fallback(fullMethod)
+	}
+}
+
+// methodOption 从全局注册的描述符中读取方法的字符串选项.
+func methodOption(fullMethod string, ext protoreflect.ExtensionType) string {
+	service, method, ok := strings.Cut(strings.TrimPrefix(fullMethod, "/"), "/")
+	if !ok {
+		return ""
+	}
+
+	desc, err := protoregistry.GlobalFiles.FindDescriptorByName(protoreflect.FullName(service))
+	if err != nil {
+		return ""
+	}
+	sd, ok := desc.(protoreflect.ServiceDescriptor)
+	if !ok {
+		return ""
+	}
+	md := sd.Methods().ByName(protoreflect.Name(method))
+	if md == nil {
+		return ""
+	}
+
+	opts, ok := md.Options().(*descriptorpb.MethodOptions)
+	if !ok || opts == nil || !proto.HasExtension(opts, ext) {
+		return ""
+	}
+	action, _ := proto.GetExtension(opts, ext).(string)
+
+	return action
+}
+
+// ProtoMessageParams 提取 proto 请求消息中已设置的顶层标量字段，同时使用字段名和 JSON 名称作为键，
+// 例如字段 user_id 可以通过 "user_id" 和 "userId" 读取. 非 proto 消息返回 nil.
+func ProtoMessageParams(fullMethod string, req any) map[string]string {
+	msg, ok := req.(proto.Message)
+	if !ok {
+		return nil
+	}
+
+	params := make(map[string]string)
+	msg.ProtoReflect().Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		if fd.IsList() || fd.IsMap() || fd.Message() != nil {
+			return true
+		}
+		value := v.String()
+		if fd.Kind() == protoreflect.EnumKind {
+			if ev := fd.Enum().Values().ByNumber(v.Enum()); ev != nil {
+				value = string(ev.Name())
+			}
+		}
+		params[string(fd.Name())] = value
+		params[fd.JSONName()] = value
+		return true
+	})
+
+	return params
+}
+
+// methodName 返回完整方法名中的方法部分.
+func methodName(fullMethod string) string {
+	if i := strings.LastIndex(fullMethod, "/"); i >= 0 {
+		return fullMethod[i+1:]
+	}
+	return fullMethod
+}
+
+// AuthzOption 用于配置 gRPC 授权拦截器.
+type AuthzOption func(*authzOptions)
+
+// authzOptions 是 gRPC 授权拦截器的配置.
+type authzOptions struct {
+	resolver      ActionResolver
+	methodActions map[string]string
+	skipMethods   map[string]struct{}
+	params        ParamsFunc
+}
+
+// WithActionResolver 设置从方法名推断动作的函数，默认使用 ConventionActionResolver.
+func WithActionResolver(resolver ActionResolver) AuthzOption {
+	return func(o *authzOptions) {
+		if resolver != nil {
+			o.resolver = resolver
+		}
+	}
+}
+
+// WithMethodActions 为指定的完整方法名显式设置动作，优先级高于 ActionResolver.
+func WithMethodActions(actions map[string]string) AuthzOption {
+	return func(o *authzOptions) {
+		for method, action := range actions {
+			o.methodActions[method] = action
+		}
+	}
+}
+
+// WithAuthzSkipMethods 设置跳过授权的完整方法名，例如健康检查接口.
+func WithAuthzSkipMethods(methods ...string) AuthzOption {
+	return func(o *authzOptions) {
+		for _, method := range methods {
+			o.skipMethods[method] = struct{}{}
+		}
+	}
+}
+
+// WithRequestParams 设置从一元调用的请求消息中提取请求参数的函数，例如 ProtoMessageParams.
+// 流式调用在授权时还没有收到请求消息，因此没有请求参数.
+func WithRequestParams(fn ParamsFunc) AuthzOption {
+	return func(o *authzOptions) {
+		o.params = fn
+	}
+}
+
+// AuthzUnaryInterceptor 是 gRPC 一元调用的授权拦截器，需要放在认证拦截器之后.
+func AuthzUnaryInterceptor(authorizer Authorizer, opts ...AuthzOption) grpc.UnaryServerInterceptor {
+	authorize := authzFunc(authorizer, opts...)
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, err := authorize(ctx, info.FullMethod, req)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// AuthzStreamInterceptor 是 gRPC 流式调用的授权拦截器，需要放在认证拦截器之后.
+func AuthzStreamInterceptor(authorizer Authorizer, opts ...AuthzOption) grpc.StreamServerInterceptor {
+	authorize := authzFunc(authorizer, opts...)
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if _, err := authorize(ss.Context(), info.FullMethod, nil); err != nil {
+			return err
+		}
+		return handler(srv, ss)
+	}
+}
+
+// authzFunc 返回授权函数，使用上下文中的用户 ID 作为 subject，完整方法名作为 object.
+// 授权函数返回包含请求参数的上下文.
+func authzFunc(authorizer Authorizer, opts ...AuthzOption) func(ctx context.Context, fullMethod string, req any) (context.Context, error) {
+	o := &authzOptions{
+		resolver:      ConventionActionResolver(),
+		methodActions: make(map[string]string),
+		skipMethods:   make(map[string]struct{}),
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return func(ctx context.Context, fullMethod string, req any) (context.Context, error) {
+		if _, skip := o.skipMethods[fullMethod]; skip {
+			return ctx, nil
+		}
+
+		// 将请求参数存放到上下文中，供 ABAC 规则使用
+		if o.params != nil && req != nil {
+			if params := o.params(fullMethod, req); len(params) > 0 {
+				ctx = contextx.WithParams(ctx, params)
+			}
+		}
+
+		subject := contextx.UserID(ctx)
+		action, ok := o.methodActions[fullMethod]
+		if !ok {
+			action = o.resolver(fullMethod)
+		}
+
+		// 记录授权上下文信息
+		log.Debugw("Build authorize context", "subject", subject, "object", fullMethod, "action", action)
+
+		// 调用授权接口进行验证
+		allowed, err := middleware.Authorize(ctx, authorizer, subject, fullMethod, action)
+		if err != nil || !allowed {
+			return ctx, errorsx.ErrPermissionDenied.WithMessage(
+				"access denied: subject=%s, object=%s, action=%s, reason=%v",
+				subject,
+				fullMethod,
+				action,
+				err,
+			).GRPCStatus().Err()
+		}
+
+		return ctx, nil
+	}
+}
@@ -0,0 +1,45 @@
+/**FileHeader
+ * @Author: Liangkang Zhang
+ * @Date: 2026/10/18 10:12:40
+ * @LastEditors: Liangkang Zhang
+ * @LastEditTime: 2026/10/18 10:12:40
+ * @Description:
+ * @Copyright: Copyright (©)}) 2026 Liangkang Zhang<lkzhang98@gmail.com>. All rights reserved. Use of this source code is governed by a MIT style license that can be found in the LICENSE file.. All rights reserved.
+ * @Email: lkzhang98@gmail.com
+ * @Repository: https://github.com/geminik12/autostack
+ */
+
+// Package middleware 定义了 Gin 中间件和 gRPC 拦截器共用的认证和授权接口，
+// 两者通过各自的类型别名引用这些接口，互不依赖.
+package middleware
+
+import (
+	"context"
+
+	"github.com/geminik12/autostack/model"
+)
+
+// UserRetriever 用于根据用户名获取用户的接口.
+type UserRetriever interface {
+	// GetUser 根据用户ID获取用户信息
+	GetUser(ctx context.Context, userID string) (*model.UserM, error)
+}
+
+// Authorizer 用于定义授权接口的实现.
+type Authorizer interface {
+	Authorize(subject, object, action string) (bool, error)
+}
+
+// ContextAuthorizer 是可以接收请求上下文的授权接口.
+// Authorizer 同时实现该接口时，中间件会传入请求上下文，例如用于在审计记录中关联请求 ID.
+type ContextAuthorizer interface {
+	AuthorizeWithContext(ctx context.Context, subject, object, action string) (bool, error)
+}
+
+// Authorize 调用授权接口，优先使用可以接收请求上下文的实现.
+func Authorize(ctx context.Context, authorizer Authorizer, subject, object, action string) (bool, error) {
+	if ca, ok := authorizer.(ContextAuthorizer); ok {
+		return ca.AuthorizeWithContext(ctx, subject, object, action)
+	}
+	return authorizer.Authorize(subject, object, action)
+}
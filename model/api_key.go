@@ -0,0 +1,33 @@
+/**FileHeader
+ * @Author: Liangkang Zhang
+ * @Date: 2026/2/20 20:15:36
+ * @LastEditors: Liangkang Zhang
+ * @LastEditTime: 2026/2/20 20:15:36
+ * @Description:
+ * @Copyright: Copyright (©)}) 2026 Liangkang Zhang<lkzhang98@gmail.com>. All rights reserved. Use of this source code is governed by a MIT style license that can be found in the LICENSE file.. All rights reserved.
+ * @Email: lkzhang98@gmail.com
+ * @Repository: https://github.com/geminik12/autostack
+ */
+package model
+
+import "time"
+
+const TableNameAPIKeyM = "api_key"
+
+// APIKeyM mapped from table <api_key>
+type APIKeyM struct {
+	ID         int64      `gorm:"column:id;primaryKey;autoIncrement:true" json:"id"`
+	Prefix     string     `gorm:"column:prefix;not null;uniqueIndex:idx_api_key_prefix;comment:API Key 前缀（用于查找）" json:"prefix"` // API Key 前缀（用于查找）
+	Hash       string     `gorm:"column:hash;not null;comment:API Key 的哈希值" json:"-"`                                           // API Key 的哈希值
+	UserID     string     `gorm:"column:userID;not null;index:idx_api_key_userID;comment:所属用户 ID" json:"userID"`                // 所属用户 ID
+	Name       string     `gorm:"column:name;not null;comment:API Key 名称" json:"name"`                                          // API Key 名称
+	Scopes     string     `gorm:"column:scopes;not null;comment:授权范围（空格分隔）" json:"scopes"`                                      // 授权范围（空格分隔）
+	ExpiresAt  *time.Time `gorm:"column:expiresAt;comment:过期时间，为空表示永不过期" json:"expiresAt"`                                      // 过期时间，为空表示永不过期
+	LastUsedAt *time.Time `gorm:"column:lastUsedAt;comment:最后使用时间" json:"lastUsedAt"`                                           // 最后使用时间
+	CreatedAt  time.Time  `gorm:"column:createdAt;not null;default:current_timestamp;comment:创建时间" json:"createdAt"`            // 创建时间
+}
+
+// TableName APIKeyM's table name
+func (*APIKeyM) TableName() string {
+	return TableNameAPIKeyM
+}
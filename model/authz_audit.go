@@ -0,0 +1,34 @@
+/**FileHeader
+ * @Author: Liangkang Zhang
+ * @Date: 2026/2/17 20:31:44
+ * @LastEditors: Liangkang Zhang
+ * @LastEditTime: 2026/2/17 20:31:44
+ * @Description:
+ * @Copyright: Copyright (©)}) 2026 Liangkang Zhang<lkzhang98@gmail.com>. All rights reserved. Use of this source code is governed by a MIT style license that can be found in the LICENSE file.. All rights reserved.
+ * @Email: lkzhang98@gmail.com
+ * @Repository: https://github.com/geminik12/autostack
+ */
+package model
+
+import "time"
+
+const TableNameAuthzAuditM = "authz_audit"
+
+// AuthzAuditM mapped from table <authz_audit>
+type AuthzAuditM struct {
+	ID        int64     `gorm:"column:id;primaryKey;autoIncrement:true" json:"id"`
+	Subject   string    `gorm:"column:subject;not null;index:idx_authz_audit_subject;comment:授权主体" json:"subject"`        // 授权主体
+	Tenant    string    `gorm:"column:tenant;not null;comment:租户 ID" json:"tenant"`                                       // 租户 ID
+	Object    string    `gorm:"column:object;not null;comment:访问的资源" json:"object"`                                       // 访问的资源
+	Action    string    `gorm:"column:action;not null;comment:执行的动作" json:"action"`                                       // 执行的动作
+	Allowed   bool      `gorm:"column:allowed;not null;comment:是否允许访问" json:"allowed"`                                    // 是否允许访问
+	Policy    string    `gorm:"column:policy;not null;comment:产生决策的策略" json:"policy"`                                     // 产生决策的策略
+	Error     string    `gorm:"column:error;not null;default:'';comment:授权检查的错误信息" json:"error"`                          // 授权检查的错误信息
+	RequestID string    `gorm:"column:requestID;not null;index:idx_authz_audit_requestID;comment:请求 ID" json:"requestID"` // 请求 ID
+	CreatedAt time.Time `gorm:"column:createdAt;not null;index:idx_authz_audit_createdAt;comment:决策时间" json:"createdAt"`  // 决策时间
+}
+
+// TableName AuthzAuditM's table name
+func (*AuthzAuditM) TableName() string {
+	return TableNameAuthzAuditM
+}
@@ -10,21 +10,28 @@
  */
 package model
 
-import "time"
+import (
+	"time"
+
+	"gorm.io/plugin/soft_delete"
+)
 
 const TableNameUserM = "user"
 
 // UserM mapped from table <user>
+// 软删除的用户 deletedAt 为删除时间（Unix 毫秒），未删除时为 0. username 和 phone 的唯一索引包含 deletedAt，
+// 因此已删除用户的用户名和手机号可以重新注册；userID 始终全局唯一，不会被新用户复用.
 type UserM struct {
-	ID        int64     `gorm:"column:id;primaryKey;autoIncrement:true" json:"id"`
-	UserID    string    `gorm:"column:userID;not null;uniqueIndex:idx_user_userID;comment:用户唯一 ID" json:"userID"`       // 用户唯一 ID
-	Username  string    `gorm:"column:username;not null;uniqueIndex:idx_user_username;comment:用户名（唯一）" json:"username"` // 用户名（唯一）
-	Password  string    `gorm:"column:password;not null;comment:用户密码（加密后）" json:"password"`                             // 用户密码（加密后）
-	Nickname  string    `gorm:"column:nickname;not null;comment:用户昵称" json:"nickname"`                                  // 用户昵称
-	Email     string    `gorm:"column:email;not null;comment:用户电子邮箱地址" json:"email"`                                    // 用户电子邮箱地址
-	Phone     string    `gorm:"column:phone;not null;uniqueIndex:idx_user_phone;comment:用户手机号" json:"phone"`            // 用户手机号
-	CreatedAt time.Time `gorm:"column:createdAt;not null;default:current_timestamp;comment:用户创建时间" json:"createdAt"`    // 用户创建时间
-	UpdatedAt time.Time `gorm:"column:updatedAt;not null;default:current_timestamp;comment:用户最后修改时间" json:"updatedAt"`  // 用户最后修改时间
+	ID        int64                 `gorm:"column:id;primaryKey;autoIncrement:true" json:"id"`
+	UserID    string                `gorm:"column:userID;not null;uniqueIndex:idx_user_userID;comment:用户唯一 ID" json:"userID"`                                                                                                     // 用户唯一 ID
+	Username  string                `gorm:"column:username;not null;uniqueIndex:idx_user_username,priority:1;comment:用户名（唯一）" json:"username"`                                                                                    // 用户名（唯一）
+	Password  string                `gorm:"column:password;not null;comment:用户密码（加密后）" json:"password"`                                                                                                                           // 用户密码（加密后）
+	Nickname  string                `gorm:"column:nickname;not null;comment:用户昵称" json:"nickname"`                                                                                                                                // 用户昵称
+	Email     string                `gorm:"column:email;not null;comment:用户电子邮箱地址" json:"email"`                                                                                                                                  // 用户电子邮箱地址
+	Phone     string                `gorm:"column:phone;not null;uniqueIndex:idx_user_phone,priority:1;comment:用户手机号" json:"phone"`                                                                                               // 用户手机号
+	CreatedAt time.Time             `gorm:"column:createdAt;not null;default:current_timestamp;comment:用户创建时间" json:"createdAt"`                                                                                                  // 用户创建时间
+	UpdatedAt time.Time             `gorm:"column:updatedAt;not null;default:current_timestamp;comment:用户最后修改时间" json:"updatedAt"`                                                                                                // 用户最后修改时间
+	DeletedAt soft_delete.DeletedAt `gorm:"column:deletedAt;not null;default:0;softDelete:milli;index:idx_user_deletedAt;uniqueIndex:idx_user_username,priority:2;uniqueIndex:idx_user_phone,priority:2;comment:用户删除时间" json:"-"` // 用户删除时间
 }
 
 // TableName UserM's table name
@@ -0,0 +1,31 @@
+/**FileHeader
+ * @Author: Liangkang Zhang
+ * @Date: 2026/2/24 20:08:31
+ * @LastEditors: Liangkang Zhang
+ * @LastEditTime: 2026/2/24 20:08:31
+ * @Description:
+ * @Copyright: Copyright (©)}) 2026 Liangkang Zhang<lkzhang98@gmail.com>. All rights reserved. Use of this source code is governed by a MIT style license that can be found in the LICENSE file.. All rights reserved.
+ * @Email: lkzhang98@gmail.com
+ * @Repository: https://github.com/geminik12/autostack
+ */
+package model
+
+import "time"
+
+const TableNameUserIdentityM = "user_identity"
+
+// UserIdentityM mapped from table <user_identity>
+type UserIdentityM struct {
+	ID        int64     `gorm:"column:id;primaryKey;autoIncrement:true" json:"id"`
+	UserID    string    `gorm:"column:userID;not null;index:idx_user_identity_userID;comment:用户唯一 ID" json:"userID"`                         // 用户唯一 ID
+	Issuer    string    `gorm:"column:issuer;not null;uniqueIndex:idx_user_identity_issuer_subject;comment:身份提供方（iss）" json:"issuer"`        // 身份提供方（iss）
+	Subject   string    `gorm:"column:subject;not null;uniqueIndex:idx_user_identity_issuer_subject;comment:身份提供方的用户标识（sub）" json:"subject"` // 身份提供方的用户标识（sub）
+	Email     string    `gorm:"column:email;not null;comment:身份提供方返回的电子邮箱地址" json:"email"`                                                   // 身份提供方返回的电子邮箱地址
+	CreatedAt time.Time `gorm:"column:createdAt;not null;default:current_timestamp;comment:创建时间" json:"createdAt"`                           // 创建时间
+	UpdatedAt time.Time `gorm:"column:updatedAt;not null;default:current_timestamp;comment:最后修改时间" json:"updatedAt"`                         // 最后修改时间
+}
+
+// TableName UserIdentityM's table name
+func (*UserIdentityM) TableName() string {
+	return TableNameUserIdentityM
+}
@@ -0,0 +1,34 @@
+/**FileHeader
+ * @Author: Liangkang Zhang
+ * @Date: 2026/2/23 20:11:27
+ * @LastEditors: Liangkang Zhang
+ * @LastEditTime: 2026/2/23 20:11:27
+ * @Description:
+ * @Copyright: Copyright (©)}) 2026 Liangkang Zhang<lkzhang98@gmail.com>. All rights reserved. Use of this source code is governed by a MIT style license that can be found in the LICENSE file.. All rights reserved.
+ * @Email: lkzhang98@gmail.com
+ * @Repository: https://github.com/geminik12/autostack
+ */
+package model
+
+import "time"
+
+const TableNameUserMFAM = "user_mfa"
+
+// UserMFAM mapped from table <user_mfa>
+type UserMFAM struct {
+	ID             int64      `gorm:"column:id;primaryKey;autoIncrement:true" json:"id"`
+	UserID         string     `gorm:"column:userID;not null;uniqueIndex:idx_user_mfa_userID;comment:用户唯一 ID" json:"userID"` // 用户唯一 ID
+	Secret         string     `gorm:"column:secret;not null;comment:TOTP 密钥（base32 编码）" json:"-"`                           // TOTP 密钥（base32 编码）
+	Enabled        bool       `gorm:"column:enabled;not null;comment:是否已完成绑定" json:"enabled"`                               // 是否已完成绑定
+	LastStep       int64      `gorm:"column:lastStep;not null;comment:最后一次使用的 TOTP 时间步，用于防止重放" json:"-"`                    // 最后一次使用的 TOTP 时间步，用于防止重放
+	RecoveryCodes  string     `gorm:"column:recoveryCodes;not null;comment:未使用的恢复码哈希（逗号分隔）" json:"-"`                       // 未使用的恢复码哈希（逗号分隔）
+	FailedAttempts int        `gorm:"column:failedAttempts;not null;default:0;comment:连续验证失败次数" json:"-"`                   // 连续验证失败次数
+	LockedUntil    *time.Time `gorm:"column:lockedUntil;comment:验证被锁定的截止时间" json:"-"`                                       // 验证被锁定的截止时间
+	CreatedAt      time.Time  `gorm:"column:createdAt;not null;default:current_timestamp;comment:创建时间" json:"createdAt"`    // 创建时间
+	UpdatedAt      time.Time  `gorm:"column:updatedAt;not null;default:current_timestamp;comment:最后修改时间" json:"updatedAt"`  // 最后修改时间
+}
+
+// TableName UserMFAM's table name
+func (*UserMFAM) TableName() string {
+	return TableNameUserMFAM
+}
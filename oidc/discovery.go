@@ -0,0 +1,68 @@
+/**FileHeader
+ * @Author: Liangkang Zhang
+ * @Date: 2026/2/24 20:15:02
+ * @LastEditors: Liangkang Zhang
+ * @LastEditTime: 2026/2/24 20:15:02
+ * @Description:
+ * @Copyright: Copyright (©)}) 2026 Liangkang Zhang<lkzhang98@gmail.com>. All rights reserved. Use of this source code is governed by a MIT style license that can be found in the LICENSE file.. All rights reserved.
+ * @Email: lkzhang98@gmail.com
+ * @Repository: https://github.com/geminik12/autostack
+ */
+package oidc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// DiscoveryPath 是 OpenID Connect Discovery 元数据的标准路径.
+const DiscoveryPath = "/.well-known/openid-configuration"
+
+// ProviderMetadata 是身份提供方通过 Discovery 发布的元数据，只包含登录流程需要的字段.
+type ProviderMetadata struct {
+	Issuer                        string   `json:"issuer"`
+	AuthorizationEndpoint         string   `json:"authorization_endpoint"`
+	TokenEndpoint                 string   `json:"token_endpoint"`
+	UserinfoEndpoint              string   `json:"userinfo_endpoint,omitempty"`
+	JWKSURI                       string   `json:"jwks_uri"`
+	ScopesSupported               []string `json:"scopes_supported,omitempty"`
+	IDTokenSigningAlgValues       []string `json:"id_token_signing_alg_values_supported,omitempty"`
+	CodeChallengeMethodsSupported []string `json:"code_challenge_methods_supported,omitempty"`
+}
+
+// discover 获取 issuer 的 Discovery 元数据，元数据中的 issuer 必须与 issuer 完全一致.
+func discover(ctx context.Context, client *http.Client, issuer string) (*ProviderMetadata, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(issuer, "/")+DiscoveryPath, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrDiscovery, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: unexpected status code %d", ErrDiscovery, resp.StatusCode)
+	}
+
+	var metadata ProviderMetadata
+	if err := json.NewDecoder(resp.Body).Decode(&metadata); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrDiscovery, err)
+	}
+
+	// OpenID Connect Discovery 1.0 第 4.3 节要求 issuer 一致，防止被其他身份提供方冒充
+	if metadata.Issuer != issuer {
+		return nil, fmt.Errorf("%w: issuer %q does not match %q", ErrDiscovery, metadata.Issuer, issuer)
+	}
+	if metadata.AuthorizationEndpoint == "" || metadata.TokenEndpoint == "" || metadata.JWKSURI == "" {
+		return nil, fmt.Errorf("%w: missing required endpoints", ErrDiscovery)
+	}
+
+	return &metadata, nil
+}
@@ -0,0 +1,111 @@
+/**FileHeader
+ * @Author: Liangkang Zhang
+ * @Date: 2026/2/24 21:50:33
+ * @LastEditors: Liangkang Zhang
+ * @LastEditTime: 2026/2/24 21:50:33
+ * @Description:
+ * @Copyright: Copyright (©)}) 2026 Liangkang Zhang<lkzhang98@gmail.com>. All rights reserved. Use of this source code is governed by a MIT style license that can be found in the LICENSE file.. All rights reserved.
+ * @Email: lkzhang98@gmail.com
+ * @Repository: https://github.com/geminik12/autostack
+ */
+package oidc
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/geminik12/autostack/core"
+	"github.com/geminik12/autostack/errorsx"
+	"github.com/geminik12/autostack/log"
+	"github.com/geminik12/autostack/token"
+	"github.com/gin-gonic/gin"
+)
+
+// CallbackRequest 是身份提供方跳转回调地址时携带的查询参数.
+type CallbackRequest struct {
+	Code             string `form:"code"`
+	State            string `form:"state"`
+	Error            string `form:"error"`
+	ErrorDescription string `form:"error_description"`
+}
+
+// CallbackResponse 是登录回调接口的响应，Token 由本服务签发.
+type CallbackResponse struct {
+	Token    string    `json:"token"`
+	ExpireAt time.Time `json:"expireAt"`
+}
+
+// HandlerOption 用于配置登录回调处理函数.
+type HandlerOption func(*handlerOptions)
+
+// handlerOptions 是登录回调处理函数的配置.
+type handlerOptions struct {
+	manager *token.Manager
+}
+
+// WithTokenManager 设置签发 token 使用的 token.Manager，默认使用 token 包的默认 Manager.
+func WithTokenManager(manager *token.Manager) HandlerOption {
+	return func(o *handlerOptions) {
+		o.manager = manager
+	}
+}
+
+// LoginHandler 返回一个 Gin 处理函数，将用户重定向到身份提供方的授权地址.
+func LoginHandler(rp *RelyingParty) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		authURL, err := rp.AuthCodeURL(c.Request.Context())
+		if err != nil {
+			core.WriteResponse(c, nil, errorsx.ErrInternal.WithMessage("%s", err.Error()))
+			return
+		}
+
+		c.Redirect(http.StatusFound, authURL)
+	}
+}
+
+// CallbackHandler 返回一个 Gin 处理函数，处理身份提供方的回调：验证 ID Token，
+// 通过 provisioner 找到或即时创建本地用户，然后使用用户 ID 签发 token，签发的 token 可以直接被 AuthnMiddleware 解析.
+func CallbackHandler(rp *RelyingParty, provisioner *Provisioner, opts ...HandlerOption) gin.HandlerFunc {
+	o := &handlerOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return func(c *gin.Context) {
+		core.HandleQueryRequest(c, func(ctx context.Context, rq *CallbackRequest) (*CallbackResponse, error) {
+			if rq.Error != "" {
+				return nil, errorsx.ErrUnauthenticated.WithMessage("%s", strings.TrimSpace(ErrAuthorization.Error()+": "+rq.Error+" "+rq.ErrorDescription))
+			}
+
+			idToken, err := rp.Exchange(ctx, rq.State, rq.Code)
+			if err != nil {
+				log.W(ctx).Errorw(err, "Failed to complete oidc login")
+				if errors.Is(err, ErrInvalidState) || errors.Is(err, ErrExchange) || errors.Is(err, ErrInvalidIDToken) {
+					return nil, errorsx.ErrUnauthenticated.WithMessage("%s", err.Error())
+				}
+				return nil, errorsx.ErrInternal.WithMessage("%s", err.Error())
+			}
+
+			user, err := provisioner.Provision(ctx, idToken)
+			if err != nil {
+				log.W(ctx).Errorw(err, "Failed to provision user", "issuer", idToken.Issuer, "subject", idToken.Subject)
+				return nil, errorsx.ErrInternal.WithMessage("%s", err.Error())
+			}
+
+			manager := o.manager
+			if manager == nil {
+				manager = token.Default()
+			}
+
+			tokenString, expireAt, err := manager.Sign(user.UserID)
+			if err != nil {
+				return nil, errorsx.ErrSignToken.WithMessage("%s", err.Error())
+			}
+
+			return &CallbackResponse{Token: tokenString, ExpireAt: expireAt}, nil
+		})
+	}
+}
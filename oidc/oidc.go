@@ -0,0 +1,376 @@
+/**FileHeader
+ * @Author: Liangkang Zhang
+ * @Date: 2026/2/24 20:52:19
+ * @LastEditors: Liangkang Zhang
+ * @LastEditTime: 2026/2/24 20:52:19
+ * @Description:
+ * @Copyright: Copyright (©)}) 2026 Liangkang Zhang<lkzhang98@gmail.com>. All rights reserved. Use of this source code is governed by a MIT style license that can be found in the LICENSE file.. All rights reserved.
+ * @Email: lkzhang98@gmail.com
+ * @Repository: https://github.com/geminik12/autostack
+ */
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/geminik12/autostack/token"
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// OIDC 登录相关的预定义错误
+var (
+	ErrDiscovery      = errors.New("oidc discovery failed")
+	ErrInvalidState   = errors.New("invalid or expired oidc state")
+	ErrAuthorization  = errors.New("authorization request was rejected by the identity provider")
+	ErrExchange       = errors.New("failed to exchange authorization code")
+	ErrInvalidIDToken = errors.New("invalid id token")
+)
+
+const (
+	// ScopeOpenID 是 OpenID Connect 请求必须包含的 scope.
+	ScopeOpenID = "openid"
+
+	defaultStateTTL = 10 * time.Minute
+)
+
+// Option 用于配置 RelyingParty.
+type Option func(*RelyingParty)
+
+// WithClientSecret 设置客户端密钥，换取 token 时使用 client_secret_basic 认证.
+// 未设置时作为公开客户端只提交 client_id，依赖 PKCE 保护授权码.
+func WithClientSecret(secret string) Option {
+	return func(rp *RelyingParty) {
+		rp.clientSecret = secret
+	}
+}
+
+// WithScopes 设置请求的 scope，默认为 openid、profile 和 email. openid 总会被包含.
+func WithScopes(scopes ...string) Option {
+	return func(rp *RelyingParty) {
+		rp.scopes = scopes
+	}
+}
+
+// WithHTTPClient 设置访问身份提供方使用的 HTTP 客户端，也用于获取 JWKS.
+func WithHTTPClient(client *http.Client) Option {
+	return func(rp *RelyingParty) {
+		if client != nil {
+			rp.client = client
+		}
+	}
+}
+
+// WithStateStore 设置登录状态的存储，默认使用 MemoryStateStore.
+func WithStateStore(store StateStore) Option {
+	return func(rp *RelyingParty) {
+		rp.states = store
+	}
+}
+
+// WithStateTTL 设置登录状态的有效期，即用户在身份提供方完成登录的最长时间，默认为 10 分钟.
+func WithStateTTL(ttl time.Duration) Option {
+	return func(rp *RelyingParty) {
+		if ttl > 0 {
+			rp.stateTTL = ttl
+		}
+	}
+}
+
+// WithLeeway 设置校验 ID Token 的 exp、nbf、iat 时允许的时钟偏差.
+func WithLeeway(leeway time.Duration) Option {
+	return func(rp *RelyingParty) {
+		rp.leeway = leeway
+	}
+}
+
+// IDToken 是验证通过的 ID Token.
+type IDToken struct {
+	Issuer            string
+	Subject           string
+	Audience          []string
+	Expiry            time.Time
+	IssuedAt          time.Time
+	Nonce             string
+	Email             string
+	EmailVerified     bool
+	Name              string
+	PreferredUsername string
+	PhoneNumber       string
+	// Claims 是 ID Token 中的全部 claims，用于读取身份提供方自定义的字段.
+	Claims jwt.MapClaims
+	// AccessToken 是与 ID Token 一起返回的访问令牌，用于调用身份提供方的 API.
+	AccessToken string
+}
+
+// RelyingParty 是 OpenID Connect 依赖方（客户端），使用授权码流程和 PKCE 完成登录：
+//
+//  1. AuthCodeURL 生成 state、nonce 和 PKCE code_verifier 并保存，返回身份提供方的授权地址；
+//  2. 用户登录后身份提供方携带 code 和 state 跳转回 redirectURL；
+//  3. Exchange 校验 state，使用 code 和 code_verifier 换取 ID Token，并根据 Discovery 发布的 JWKS
+//     验证签名、iss、aud、exp 和 nonce.
+//
+// 身份提供方的 ID Token 必须使用非对称算法签名并在头部携带 kid.
+type RelyingParty struct {
+	clientID     string
+	clientSecret string
+	redirectURL  string
+	scopes       []string
+	client       *http.Client
+	states       StateStore
+	stateTTL     time.Duration
+	leeway       time.Duration
+
+	metadata *ProviderMetadata
+	verifier *token.Manager
+}
+
+// NewRelyingParty 通过 issuer 的 Discovery 元数据创建一个 RelyingParty.
+// clientID 是在身份提供方注册的客户端 ID，redirectURL 是注册的回调地址.
+func NewRelyingParty(ctx context.Context, issuer, clientID, redirectURL string, opts ...Option) (*RelyingParty, error) {
+	rp := &RelyingParty{
+		clientID:    clientID,
+		redirectURL: redirectURL,
+		scopes:      []string{ScopeOpenID, "profile", "email"},
+		client:      &http.Client{Timeout: 10 * time.Second},
+		states:      NewMemoryStateStore(),
+		stateTTL:    defaultStateTTL,
+	}
+	for _, opt := range opts {
+		opt(rp)
+	}
+	if !slices.Contains(rp.scopes, ScopeOpenID) {
+		rp.scopes = append([]string{ScopeOpenID}, rp.scopes...)
+	}
+
+	metadata, err := discover(ctx, rp.client, issuer)
+	if err != nil {
+		return nil, err
+	}
+	rp.metadata = metadata
+
+	// 复用 token.Manager 校验 ID Token 的签名和注册声明，验证密钥按 kid 从 JWKS 加载
+	rp.verifier = token.NewManager("",
+		token.WithRemoteKeySet(token.NewRemoteKeySet(metadata.JWKSURI, token.WithHTTPClient(rp.client))),
+		token.WithIssuer(metadata.Issuer),
+		token.WithAudience(clientID),
+		token.WithLeeway(rp.leeway),
+	)
+
+	return rp, nil
+}
+
+// Metadata 返回身份提供方的 Discovery 元数据.
+func (rp *RelyingParty) Metadata() ProviderMetadata {
+	return *rp.metadata
+}
+
+// AuthCodeURL 生成一次登录请求的 state、nonce 和 PKCE code_verifier 并保存，返回跳转到身份提供方的授权地址.
+func (rp *RelyingParty) AuthCodeURL(ctx context.Context) (string, error) {
+	state, err := randomString()
+	if err != nil {
+		return "", err
+	}
+	nonce, err := randomString()
+	if err != nil {
+		return "", err
+	}
+	verifier, err := randomString()
+	if err != nil {
+		return "", err
+	}
+
+	as := &AuthState{Nonce: nonce, CodeVerifier: verifier, ExpiresAt: time.Now().Add(rp.stateTTL)}
+	if err := rp.states.Save(ctx, state, as); err != nil {
+		return "", err
+	}
+
+	u, err := url.Parse(rp.metadata.AuthorizationEndpoint)
+	if err != nil {
+		return "", err
+	}
+
+	query := u.Query()
+	query.Set("response_type", "code")
+	query.Set("client_id", rp.clientID)
+	query.Set("redirect_uri", rp.redirectURL)
+	query.Set("scope", strings.Join(rp.scopes, " "))
+	query.Set("state", state)
+	query.Set("nonce", nonce)
+	query.Set("code_challenge", codeChallenge(verifier))
+	query.Set("code_challenge_method", "S256")
+	u.RawQuery = query.Encode()
+
+	return u.String(), nil
+}
+
+// tokenResponse 是 token 端点的响应.
+type tokenResponse struct {
+	AccessToken      string `json:"access_token"`
+	TokenType        string `json:"token_type"`
+	IDToken          string `json:"id_token"`
+	ExpiresIn        int64  `json:"expires_in"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+// Exchange 校验回调中的 state，使用授权码换取并验证 ID Token. 每个 state 只能使用一次.
+func (rp *RelyingParty) Exchange(ctx context.Context, state, code string) (*IDToken, error) {
+	as, err := rp.states.Take(ctx, state)
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", rp.redirectURL)
+	form.Set("code_verifier", as.CodeVerifier)
+	if rp.clientSecret == "" {
+		form.Set("client_id", rp.clientID)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, rp.metadata.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if rp.clientSecret != "" {
+		// RFC 6749 第 2.3.1 节要求先对客户端 ID 和密钥进行 URL 编码
+		req.SetBasicAuth(url.QueryEscape(rp.clientID), url.QueryEscape(rp.clientSecret))
+	}
+
+	resp, err := rp.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrExchange, err)
+	}
+	defer resp.Body.Close()
+
+	var tr tokenResponse
+	if err := json.NewDecoder(resp.Body).Decode(&tr); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrExchange, err)
+	}
+	if resp.StatusCode != http.StatusOK || tr.Error != "" {
+		return nil, fmt.Errorf("%w: status %d: %s %s", ErrExchange, resp.StatusCode, tr.Error, tr.ErrorDescription)
+	}
+	if tr.IDToken == "" {
+		return nil, fmt.Errorf("%w: missing id_token in token response", ErrInvalidIDToken)
+	}
+
+	idToken, err := rp.verifyIDToken(tr.IDToken, as.Nonce)
+	if err != nil {
+		return nil, err
+	}
+	idToken.AccessToken = tr.AccessToken
+
+	return idToken, nil
+}
+
+// verifyIDToken 按照 OpenID Connect Core 1.0 第 3.1.3.7 节验证 ID Token.
+func (rp *RelyingParty) verifyIDToken(raw, nonce string) (*IDToken, error) {
+	// 签名、iss、aud、exp、nbf、iat 由 token.Manager 校验
+	claims, err := rp.verifier.GetClaims(raw)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidIDToken, err)
+	}
+
+	t := &IDToken{
+		Issuer:            stringClaim(claims, "iss"),
+		Subject:           stringClaim(claims, "sub"),
+		Audience:          audienceOf(claims),
+		Nonce:             stringClaim(claims, "nonce"),
+		Email:             stringClaim(claims, "email"),
+		Name:              stringClaim(claims, "name"),
+		PreferredUsername: stringClaim(claims, "preferred_username"),
+		PhoneNumber:       stringClaim(claims, "phone_number"),
+		Claims:            claims,
+	}
+	t.EmailVerified, _ = claims["email_verified"].(bool)
+
+	var ok bool
+	if t.Expiry, ok = timeClaim(claims, "exp"); !ok {
+		return nil, fmt.Errorf("%w: missing exp", ErrInvalidIDToken)
+	}
+	if t.IssuedAt, ok = timeClaim(claims, "iat"); !ok {
+		return nil, fmt.Errorf("%w: missing iat", ErrInvalidIDToken)
+	}
+	if t.Subject == "" {
+		return nil, fmt.Errorf("%w: missing sub", ErrInvalidIDToken)
+	}
+	// 有多个受众时 azp 必须是本客户端
+	if azp, exists := claims["azp"]; (exists || len(t.Audience) > 1) && azp != rp.clientID {
+		return nil, fmt.Errorf("%w: invalid azp", ErrInvalidIDToken)
+	}
+	if subtle.ConstantTimeCompare([]byte(t.Nonce), []byte(nonce)) != 1 {
+		return nil, fmt.Errorf("%w: nonce mismatch", ErrInvalidIDToken)
+	}
+
+	return t, nil
+}
+
+// randomString 返回 32 字节随机数的 base64url 编码，长度为 43，满足 PKCE code_verifier 的要求.
+func randomString() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// codeChallenge 返回 code_verifier 的 S256 code_challenge.
+func codeChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// stringClaim 读取字符串类型的 claim.
+func stringClaim(claims jwt.MapClaims, name string) string {
+	s, _ := claims[name].(string)
+	return s
+}
+
+// timeClaim 读取以 Unix 时间戳表示的时间类 claim.
+func timeClaim(claims jwt.MapClaims, name string) (time.Time, bool) {
+	switch v := claims[name].(type) {
+	case float64:
+		return time.Unix(int64(v), 0), true
+	case json.Number:
+		n, err := v.Int64()
+		if err != nil {
+			return time.Time{}, false
+		}
+		return time.Unix(n, 0), true
+	default:
+		return time.Time{}, false
+	}
+}
+
+// audienceOf 读取 aud claim，aud 可以是字符串或字符串数组.
+func audienceOf(claims jwt.MapClaims) []string {
+	switch v := claims["aud"].(type) {
+	case string:
+		return []string{v}
+	case []any:
+		aud := make([]string, 0, len(v))
+		for _, a := range v {
+			if s, ok := a.(string); ok {
+				aud = append(aud, s)
+			}
+		}
+		return aud
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,172 @@
+package oidc_test
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"path/filepath"
+	"testing"
+
+	"github.com/geminik12/autostack/model"
+	"github.com/geminik12/autostack/oidc"
+	"github.com/geminik12/autostack/oidc/oidctest"
+	"github.com/geminik12/autostack/store"
+	"github.com/glebarez/sqlite"
+	"github.com/golang-jwt/jwt/v4"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+const (
+	testClientID    = "autostack"
+	testRedirectURL = "http://localhost:8080/auth/oidc/callback"
+)
+
+// newTestRelyingParty 启动一个进程内的身份提供方并创建对应的 RelyingParty.
+func newTestRelyingParty(t *testing.T) (*oidctest.Server, *oidc.RelyingParty) {
+	t.Helper()
+
+	srv := oidctest.NewServer(testClientID)
+	t.Cleanup(srv.Close)
+
+	rp, err := oidc.NewRelyingParty(context.Background(), srv.Issuer(), testClientID, testRedirectURL)
+	if err != nil {
+		t.Fatalf("NewRelyingParty: %v", err)
+	}
+
+	return srv, rp
+}
+
+// newTestProvisioner 创建一个使用临时 SQLite 数据库的 Provisioner.
+func newTestProvisioner(t *testing.T) *oidc.Provisioner {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "oidc.db")), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	if err := db.AutoMigrate(&model.UserM{}); err != nil {
+		t.Fatalf("AutoMigrate: %v", err)
+	}
+
+	identities, err := oidc.NewGormIdentityStore(db)
+	if err != nil {
+		t.Fatalf("NewGormIdentityStore: %v", err)
+	}
+
+	return oidc.NewProvisioner(store.NewGormUserStore(db), identities)
+}
+
+// login 完成一次授权码流程并返回 ID Token.
+func login(t *testing.T, srv *oidctest.Server, rp *oidc.RelyingParty) *oidc.IDToken {
+	t.Helper()
+
+	authURL, err := rp.AuthCodeURL(context.Background())
+	if err != nil {
+		t.Fatalf("AuthCodeURL: %v", err)
+	}
+
+	code, state, err := srv.Authorize(authURL)
+	if err != nil {
+		t.Fatalf("Authorize: %v", err)
+	}
+
+	idToken, err := rp.Exchange(context.Background(), state, code)
+	if err != nil {
+		t.Fatalf("Exchange: %v", err)
+	}
+
+	return idToken
+}
+
+func TestLoginAndProvision(t *testing.T) {
+	srv, rp := newTestRelyingParty(t)
+	provisioner := newTestProvisioner(t)
+	ctx := context.Background()
+
+	idToken := login(t, srv, rp)
+	if idToken.Issuer != srv.Issuer() || idToken.Subject != "alice" {
+		t.Fatalf("id token iss=%s sub=%s, want iss=%s sub=alice", idToken.Issuer, idToken.Subject, srv.Issuer())
+	}
+
+	user, err := provisioner.Provision(ctx, idToken)
+	if err != nil {
+		t.Fatalf("Provision: %v", err)
+	}
+	if user.Username != "alice" || user.Email != "alice@example.com" {
+		t.Errorf("provisioned user = %s <%s>, want alice <alice@example.com>", user.Username, user.Email)
+	}
+
+	// 同一个身份再次登录时返回同一个本地用户
+	again, err := provisioner.Provision(ctx, login(t, srv, rp))
+	if err != nil {
+		t.Fatalf("Provision: %v", err)
+	}
+	if again.UserID != user.UserID {
+		t.Errorf("second login userID = %s, want %s", again.UserID, user.UserID)
+	}
+
+	// 同用户名的其他身份创建新的本地用户，用户名追加后缀
+	srv.SetClaims(jwt.MapClaims{"sub": "alice-2", "preferred_username": "alice"})
+	other, err := provisioner.Provision(ctx, login(t, srv, rp))
+	if err != nil {
+		t.Fatalf("Provision: %v", err)
+	}
+	if other.UserID == user.UserID || other.Username == user.Username {
+		t.Errorf("other identity provisioned as %s (%s), want a new user", other.UserID, other.Username)
+	}
+}
+
+func TestExchangeRejectsReusedState(t *testing.T) {
+	srv, rp := newTestRelyingParty(t)
+	ctx := context.Background()
+
+	authURL, err := rp.AuthCodeURL(ctx)
+	if err != nil {
+		t.Fatalf("AuthCodeURL: %v", err)
+	}
+	code, state, err := srv.Authorize(authURL)
+	if err != nil {
+		t.Fatalf("Authorize: %v", err)
+	}
+
+	if _, err := rp.Exchange(ctx, state, code); err != nil {
+		t.Fatalf("Exchange: %v", err)
+	}
+	if _, err := rp.Exchange(ctx, state, code); !errors.Is(err, oidc.ErrInvalidState) {
+		t.Errorf("Exchange with reused state error = %v, want %v", err, oidc.ErrInvalidState)
+	}
+	if _, err := rp.Exchange(ctx, "unknown", code); !errors.Is(err, oidc.ErrInvalidState) {
+		t.Errorf("Exchange with unknown state error = %v, want %v", err, oidc.ErrInvalidState)
+	}
+}
+
+func TestExchangeRejectsNonceMismatch(t *testing.T) {
+	srv, rp := newTestRelyingParty(t)
+	ctx := context.Background()
+
+	authURL, err := rp.AuthCodeURL(ctx)
+	if err != nil {
+		t.Fatalf("AuthCodeURL: %v", err)
+	}
+
+	// 篡改授权请求中的 nonce，身份提供方签发的 ID Token 将携带与 state 中不一致的 nonce
+	u, err := url.Parse(authURL)
+	if err != nil {
+		t.Fatalf("parse auth url: %v", err)
+	}
+	query := u.Query()
+	query.Set("nonce", "injected")
+	u.RawQuery = query.Encode()
+
+	code, state, err := srv.Authorize(u.String())
+	if err != nil {
+		t.Fatalf("Authorize: %v", err)
+	}
+
+	if _, err := rp.Exchange(ctx, state, code); !errors.Is(err, oidc.ErrInvalidIDToken) {
+		t.Errorf("Exchange with mismatched nonce error = %v, want %v", err, oidc.ErrInvalidIDToken)
+	}
+}
@@ -0,0 +1,296 @@
+/**FileHeader
+ * @Author: Liangkang Zhang
+ * @Date: 2026/2/24 22:16:08
+ * @LastEditors: Liangkang Zhang
+ * @LastEditTime: 2026/2/24 22:16:08
+ * @Description:
+ * @Copyright: Copyright (©)}) 2026 Liangkang Zhang<lkzhang98@gmail.com>. All rights reserved. Use of this source code is governed by a MIT style license that can be found in the LICENSE file.. All rights reserved.
+ * @Email: lkzhang98@gmail.com
+ * @Repository: https://github.com/geminik12/autostack
+ */
+
+// Package oidctest 提供一个进程内的 OpenID Connect 身份提供方，用于测试 oidc 包的登录流程.
+package oidctest
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/subtle"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/geminik12/autostack/oidc"
+	"github.com/geminik12/autostack/token"
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// 身份提供方的端点路径.
+const (
+	AuthorizePath = "/authorize"
+	TokenPath     = "/token"
+	JWKSPath      = token.JWKSPath
+)
+
+// Option 用于配置 Server.
+type Option func(*Server)
+
+// WithClientSecret 设置客户端密钥，设置后 token 端点要求 client_secret_basic 认证.
+func WithClientSecret(secret string) Option {
+	return func(s *Server) {
+		s.clientSecret = secret
+	}
+}
+
+// WithClaims 设置签发的 ID Token 中用户相关的 claims，例如 sub、email、preferred_username.
+func WithClaims(claims jwt.MapClaims) Option {
+	return func(s *Server) {
+		s.claims = claims
+	}
+}
+
+// authRequest 是授权端点签发的授权码对应的请求.
+type authRequest struct {
+	clientID      string
+	redirectURI   string
+	nonce         string
+	codeChallenge string
+	claims        jwt.MapClaims
+}
+
+// Server 是一个进程内的 OpenID Connect 身份提供方，支持 Discovery、JWKS、授权码流程和 PKCE（S256）.
+// 授权端点不展示登录页面，直接为当前 claims 对应的用户签发授权码.
+type Server struct {
+	*httptest.Server
+
+	clientID     string
+	clientSecret string
+	signer       *token.Manager
+
+	mu     sync.Mutex
+	claims jwt.MapClaims
+	codes  map[string]*authRequest
+}
+
+// NewServer 启动一个只接受 clientID 的身份提供方，使用完毕后需要调用 Close.
+// 默认用户的 claims 为 sub=alice、preferred_username=alice、email=alice@example.com.
+func NewServer(clientID string, opts ...Option) *Server {
+	s := &Server{
+		clientID: clientID,
+		claims: jwt.MapClaims{
+			"sub":                "alice",
+			"preferred_username": "alice",
+			"name":               "Alice",
+			"email":              "alice@example.com",
+			"email_verified":     true,
+		},
+		codes: make(map[string]*authRequest),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	keyring := newKeyring()
+	s.signer = token.NewManager("", token.WithKeyring(keyring), token.WithExpiration(time.Hour))
+
+	engine := gin.New()
+	engine.GET(oidc.DiscoveryPath, s.discovery)
+	engine.GET(AuthorizePath, s.authorize)
+	engine.POST(TokenPath, s.token)
+	engine.GET(JWKSPath, token.JWKSHandler(keyring))
+	s.Server = httptest.NewServer(engine)
+
+	return s
+}
+
+// Issuer 返回身份提供方的 issuer.
+func (s *Server) Issuer() string {
+	return s.URL
+}
+
+// SetClaims 设置之后签发的 ID Token 中用户相关的 claims，用于模拟不同用户登录.
+func (s *Server) SetClaims(claims jwt.MapClaims) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.claims = claims
+}
+
+// Authorize 模拟用户在浏览器中访问 authURL 并完成登录，返回身份提供方回调时携带的 code 和 state.
+func (s *Server) Authorize(authURL string) (code, state string, err error) {
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+
+	resp, err := client.Get(authURL)
+	if err != nil {
+		return "", "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusFound {
+		return "", "", fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	location, err := url.Parse(resp.Header.Get("Location"))
+	if err != nil {
+		return "", "", err
+	}
+
+	query := location.Query()
+	if e := query.Get("error"); e != "" {
+		return "", "", errors.New(e)
+	}
+
+	return query.Get("code"), query.Get("state"), nil
+}
+
+// discovery 返回 Discovery 元数据.
+func (s *Server) discovery(c *gin.Context) {
+	c.JSON(http.StatusOK, oidc.ProviderMetadata{
+		Issuer:                        s.URL,
+		AuthorizationEndpoint:         s.URL + AuthorizePath,
+		TokenEndpoint:                 s.URL + TokenPath,
+		JWKSURI:                       s.URL + JWKSPath,
+		ScopesSupported:               []string{oidc.ScopeOpenID, "profile", "email"},
+		IDTokenSigningAlgValues:       []string{jwt.SigningMethodRS256.Alg()},
+		CodeChallengeMethodsSupported: []string{"S256"},
+	})
+}
+
+// authorize 校验授权请求并签发授权码，然后重定向到 redirect_uri.
+func (s *Server) authorize(c *gin.Context) {
+	redirectURI, err := url.Parse(c.Query("redirect_uri"))
+	if err != nil || c.Query("client_id") != s.clientID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid_request"})
+		return
+	}
+
+	query := redirectURI.Query()
+	query.Set("state", c.Query("state"))
+
+	switch {
+	case c.Query("response_type") != "code":
+		query.Set("error", "unsupported_response_type")
+	case c.Query("code_challenge") == "" || c.Query("code_challenge_method") != "S256":
+		query.Set("error", "invalid_request")
+	default:
+		code := randomString()
+
+		s.mu.Lock()
+		s.codes[code] = &authRequest{
+			clientID:      s.clientID,
+			redirectURI:   c.Query("redirect_uri"),
+			nonce:         c.Query("nonce"),
+			codeChallenge: c.Query("code_challenge"),
+			claims:        maps.Clone(s.claims),
+		}
+		s.mu.Unlock()
+
+		query.Set("code", code)
+	}
+
+	redirectURI.RawQuery = query.Encode()
+	c.Redirect(http.StatusFound, redirectURI.String())
+}
+
+// token 使用授权码签发 ID Token，授权码只能使用一次.
+func (s *Server) token(c *gin.Context) {
+	if c.PostForm("grant_type") != "authorization_code" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "unsupported_grant_type"})
+		return
+	}
+
+	if !s.authenticateClient(c) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid_client"})
+		return
+	}
+
+	s.mu.Lock()
+	rq, ok := s.codes[c.PostForm("code")]
+	delete(s.codes, c.PostForm("code"))
+	s.mu.Unlock()
+
+	if !ok || rq.redirectURI != c.PostForm("redirect_uri") {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid_grant"})
+		return
+	}
+
+	sum := sha256.Sum256([]byte(c.PostForm("code_verifier")))
+	if subtle.ConstantTimeCompare([]byte(base64.RawURLEncoding.EncodeToString(sum[:])), []byte(rq.codeChallenge)) != 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid_grant", "error_description": "PKCE verification failed"})
+		return
+	}
+
+	claims := maps.Clone(rq.claims)
+	claims["iss"] = s.URL
+	claims["aud"] = rq.clientID
+	if rq.nonce != "" {
+		claims["nonce"] = rq.nonce
+	}
+
+	idToken, expireAt, err := s.signer.SignWithClaims(claims)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server_error", "error_description": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"access_token": randomString(),
+		"token_type":   "Bearer",
+		"id_token":     idToken,
+		"expires_in":   int64(time.Until(expireAt).Seconds()),
+	})
+}
+
+// authenticateClient 校验 token 请求的客户端身份.
+func (s *Server) authenticateClient(c *gin.Context) bool {
+	if s.clientSecret == "" {
+		return c.PostForm("client_id") == s.clientID
+	}
+
+	id, secret, ok := c.Request.BasicAuth()
+	if !ok {
+		return false
+	}
+	id, _ = url.QueryUnescape(id)
+	secret, _ = url.QueryUnescape(secret)
+	return id == s.clientID && subtle.ConstantTimeCompare([]byte(secret), []byte(s.clientSecret)) == 1
+}
+
+// newKeyring 生成一个使用 RS256 签名的 keyring.
+func newKeyring() *token.Keyring {
+	priv, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		panic(fmt.Sprintf("oidctest: failed to generate key: %v", err))
+	}
+	block := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(priv)})
+
+	key, err := token.NewKey("oidctest", jwt.SigningMethodRS256.Alg(), string(block))
+	if err != nil {
+		panic(fmt.Sprintf("oidctest: failed to create key: %v", err))
+	}
+	keyring, err := token.NewKeyring(key)
+	if err != nil {
+		panic(fmt.Sprintf("oidctest: failed to create keyring: %v", err))
+	}
+
+	return keyring
+}
+
+// randomString 返回一个随机字符串.
+func randomString() string {
+	b := make([]byte, 24)
+	_, _ = rand.Read(b)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
@@ -0,0 +1,257 @@
+/**FileHeader
+ * @Author: Liangkang Zhang
+ * @Date: 2026/2/24 21:24:56
+ * @LastEditors: Liangkang Zhang
+ * @LastEditTime: 2026/2/24 21:24:56
+ * @Description:
+ * @Copyright: Copyright (©)}) 2026 Liangkang Zhang<lkzhang98@gmail.com>. All rights reserved. Use of this source code is governed by a MIT style license that can be found in the LICENSE file.. All rights reserved.
+ * @Email: lkzhang98@gmail.com
+ * @Repository: https://github.com/geminik12/autostack
+ */
+package oidc
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"sync"
+
+	"github.com/geminik12/autostack/log"
+	"github.com/geminik12/autostack/model"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ErrIdentityNotFound 表示身份提供方的用户还没有关联本地用户.
+var ErrIdentityNotFound = errors.New("identity not found")
+
+// IdentityStore 定义了身份提供方用户（iss + sub）与本地用户关联关系的存储接口.
+type IdentityStore interface {
+	// GetIdentity 根据 issuer 和 subject 获取关联关系，不存在时返回 ErrIdentityNotFound.
+	GetIdentity(ctx context.Context, issuer, subject string) (*model.UserIdentityM, error)
+	// CreateIdentity 创建关联关系.
+	CreateIdentity(ctx context.Context, identity *model.UserIdentityM) error
+}
+
+// UserIdentityCreator 是可以在一个事务中创建用户及其关联关系的 IdentityStore，GormIdentityStore 实现了该接口.
+// IdentityStore 实现该接口时，Provisioner 即时创建用户不会留下没有关联关系的本地用户.
+type UserIdentityCreator interface {
+	// CreateUserWithIdentity 在一个事务中创建用户和关联关系.
+	CreateUserWithIdentity(ctx context.Context, user *model.UserM, identity *model.UserIdentityM) error
+}
+
+// UserStore 定义了即时创建用户需要的用户存储接口，store.UserStore 满足该接口.
+type UserStore interface {
+	// GetUser 根据用户 ID 获取用户.
+	GetUser(ctx context.Context, userID string) (*model.UserM, error)
+	// GetUserByUsername 根据用户名获取用户，不存在时返回错误.
+	GetUserByUsername(ctx context.Context, username string) (*model.UserM, error)
+	// CreateUser 创建用户.
+	CreateUser(ctx context.Context, user *model.UserM) error
+}
+
+// UserMapper 根据 ID Token 构造即时创建的本地用户.
+type UserMapper func(ctx context.Context, idToken *IDToken) (*model.UserM, error)
+
+// ProvisionOption 用于配置 Provisioner.
+type ProvisionOption func(*Provisioner)
+
+// WithUserMapper 设置即时创建用户时使用的 UserMapper，默认为 DefaultUserMapper.
+func WithUserMapper(mapper UserMapper) ProvisionOption {
+	return func(p *Provisioner) {
+		p.mapper = mapper
+	}
+}
+
+// Provisioner 将身份提供方的用户映射为本地用户 model.UserM.
+// 第一次登录时即时创建（JIT）本地用户并记录关联关系，之后的登录通过 iss + sub 找到同一个本地用户.
+// 不会根据邮箱关联已有的本地用户，避免身份提供方中同邮箱的账号接管本地账号.
+// IdentityStore 实现了 UserIdentityCreator 时用户和关联关系在一个事务中创建，否则依次创建.
+type Provisioner struct {
+	users      UserStore
+	identities IdentityStore
+	mapper     UserMapper
+}
+
+// NewProvisioner 创建一个 Provisioner.
+func NewProvisioner(users UserStore, identities IdentityStore, opts ...ProvisionOption) *Provisioner {
+	p := &Provisioner{users: users, identities: identities, mapper: DefaultUserMapper}
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	return p
+}
+
+// Provision 返回 ID Token 对应的本地用户，用户不存在时即时创建.
+func (p *Provisioner) Provision(ctx context.Context, idToken *IDToken) (*model.UserM, error) {
+	identity, err := p.identities.GetIdentity(ctx, idToken.Issuer, idToken.Subject)
+	if err == nil {
+		return p.users.GetUser(ctx, identity.UserID)
+	}
+	if !errors.Is(err, ErrIdentityNotFound) {
+		return nil, err
+	}
+
+	user, err := p.mapper(ctx, idToken)
+	if err != nil {
+		return nil, err
+	}
+
+	// 用户名已被其他本地用户使用时追加根据 iss + sub 计算的后缀
+	if _, err := p.users.GetUserByUsername(ctx, user.Username); err == nil {
+		user.Username += "-" + identitySuffix(idToken.Issuer, idToken.Subject)
+	}
+
+	identity = &model.UserIdentityM{
+		UserID:  user.UserID,
+		Issuer:  idToken.Issuer,
+		Subject: idToken.Subject,
+		Email:   idToken.Email,
+	}
+	if err := p.create(ctx, user, identity); err != nil {
+		// 同一个 iss + sub 的并发回调可能已经创建了关联关系，此时返回已关联的本地用户
+		if existing, getErr := p.identities.GetIdentity(ctx, idToken.Issuer, idToken.Subject); getErr == nil {
+			return p.users.GetUser(ctx, existing.UserID)
+		}
+		return nil, err
+	}
+
+	log.W(ctx).Infow("Provisioned user from identity provider", "userID", user.UserID, "issuer", idToken.Issuer, "subject", idToken.Subject)
+
+	return user, nil
+}
+
+// create 创建用户及其关联关系，IdentityStore 支持时在一个事务中完成.
+func (p *Provisioner) create(ctx context.Context, user *model.UserM, identity *model.UserIdentityM) error {
+	if creator, ok := p.identities.(UserIdentityCreator); ok {
+		return creator.CreateUserWithIdentity(ctx, user, identity)
+	}
+
+	if err := p.users.CreateUser(ctx, user); err != nil {
+		return err
+	}
+	return p.identities.CreateIdentity(ctx, identity)
+}
+
+// DefaultUserMapper 使用 ID Token 的标准 claims 构造本地用户：
+// 用户名依次使用 preferred_username、email 和 sub，昵称使用 name，用户 ID 为随机的 UUID.
+// 用户没有设置密码，只能通过身份提供方登录.
+func DefaultUserMapper(ctx context.Context, idToken *IDToken) (*model.UserM, error) {
+	user := &model.UserM{
+		UserID:   uuid.New().String(),
+		Username: firstNonEmpty(idToken.PreferredUsername, idToken.Email, idToken.Subject),
+		Nickname: firstNonEmpty(idToken.Name, idToken.PreferredUsername),
+		Email:    idToken.Email,
+		Phone:    idToken.PhoneNumber,
+	}
+	// phone 列有唯一索引，身份提供方没有返回手机号时使用用户 ID 占位
+	if user.Phone == "" {
+		user.Phone = user.UserID
+	}
+
+	return user, nil
+}
+
+// identitySuffix 返回根据 issuer 和 subject 计算的 8 位后缀.
+func identitySuffix(issuer, subject string) string {
+	sum := sha256.Sum256([]byte(issuer + "\x00" + subject))
+	return hex.EncodeToString(sum[:4])
+}
+
+// firstNonEmpty 返回第一个非空字符串.
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// MemoryIdentityStore 是基于内存的关联关系存储，适用于测试.
+type MemoryIdentityStore struct {
+	mu         sync.RWMutex
+	identities map[string]model.UserIdentityM
+}
+
+var _ IdentityStore = (*MemoryIdentityStore)(nil)
+
+// NewMemoryIdentityStore 创建一个基于内存的关联关系存储.
+func NewMemoryIdentityStore() *MemoryIdentityStore {
+	return &MemoryIdentityStore{identities: make(map[string]model.UserIdentityM)}
+}
+
+// GetIdentity 根据 issuer 和 subject 获取关联关系.
+func (s *MemoryIdentityStore) GetIdentity(ctx context.Context, issuer, subject string) (*model.UserIdentityM, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	identity, ok := s.identities[issuer+"\x00"+subject]
+	if !ok {
+		return nil, ErrIdentityNotFound
+	}
+	return &identity, nil
+}
+
+// CreateIdentity 创建关联关系.
+func (s *MemoryIdentityStore) CreateIdentity(ctx context.Context, identity *model.UserIdentityM) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := identity.Issuer + "\x00" + identity.Subject
+	if _, ok := s.identities[key]; ok {
+		return errors.New("identity already exists")
+	}
+	s.identities[key] = *identity
+	return nil
+}
+
+// GormIdentityStore 是基于 gorm 的关联关系存储，关联关系保存在 user_identity 表.
+type GormIdentityStore struct {
+	db *gorm.DB
+}
+
+var (
+	_ IdentityStore       = (*GormIdentityStore)(nil)
+	_ UserIdentityCreator = (*GormIdentityStore)(nil)
+)
+
+// NewGormIdentityStore 创建一个基于 gorm 的关联关系存储，user_identity 表不存在时会自动创建.
+func NewGormIdentityStore(db *gorm.DB) (*GormIdentityStore, error) {
+	if err := db.AutoMigrate(&model.UserIdentityM{}); err != nil {
+		return nil, err
+	}
+
+	return &GormIdentityStore{db: db}, nil
+}
+
+// GetIdentity 根据 issuer 和 subject 获取关联关系.
+func (s *GormIdentityStore) GetIdentity(ctx context.Context, issuer, subject string) (*model.UserIdentityM, error) {
+	var identity model.UserIdentityM
+	err := s.db.WithContext(ctx).Where("issuer = ? AND subject = ?", issuer, subject).First(&identity).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrIdentityNotFound
+		}
+		return nil, err
+	}
+	return &identity, nil
+}
+
+// CreateIdentity 创建关联关系.
+func (s *GormIdentityStore) CreateIdentity(ctx context.Context, identity *model.UserIdentityM) error {
+	return s.db.WithContext(ctx).Create(identity).Error
+}
+
+// CreateUserWithIdentity 在一个事务中创建用户和关联关系，用户保存在 user 表.
+func (s *GormIdentityStore) CreateUserWithIdentity(ctx context.Context, user *model.UserM, identity *model.UserIdentityM) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(user).Error; err != nil {
+			return err
+		}
+		return tx.Create(identity).Error
+	})
+}
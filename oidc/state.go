@@ -0,0 +1,128 @@
+/**FileHeader
+ * @Author: Liangkang Zhang
+ * @Date: 2026/2/24 20:31:47
+ * @LastEditors: Liangkang Zhang
+ * @LastEditTime: 2026/2/24 20:31:47
+ * @Description:
+ * @Copyright: Copyright (©)}) 2026 Liangkang Zhang<lkzhang98@gmail.com>. All rights reserved. Use of this source code is governed by a MIT style license that can be found in the LICENSE file.. All rights reserved.
+ * @Email: lkzhang98@gmail.com
+ * @Repository: https://github.com/geminik12/autostack
+ */
+package oidc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	redis "github.com/redis/go-redis/v9"
+)
+
+// AuthState 是一次登录请求在跳转到身份提供方之前保存的状态，回调时根据 state 参数取回.
+type AuthState struct {
+	// Nonce 会出现在 ID Token 中，用于防止 ID Token 重放.
+	Nonce string `json:"nonce"`
+	// CodeVerifier 是 PKCE 的 code_verifier，换取 token 时提交.
+	CodeVerifier string `json:"codeVerifier"`
+	// ExpiresAt 是状态的过期时间.
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// StateStore 定义了登录状态的存储接口，每个 state 只能被取回一次.
+type StateStore interface {
+	// Save 保存 state 对应的登录状态.
+	Save(ctx context.Context, state string, s *AuthState) error
+	// Take 取回并删除 state 对应的登录状态，不存在或已过期时返回 ErrInvalidState.
+	Take(ctx context.Context, state string) (*AuthState, error)
+}
+
+// MemoryStateStore 是基于内存的登录状态存储，适用于单实例部署和测试.
+// 多实例部署时登录回调可能由其他实例处理，应使用 RedisStateStore.
+type MemoryStateStore struct {
+	mu     sync.Mutex
+	states map[string]*AuthState
+}
+
+var _ StateStore = (*MemoryStateStore)(nil)
+
+// NewMemoryStateStore 创建一个基于内存的登录状态存储.
+func NewMemoryStateStore() *MemoryStateStore {
+	return &MemoryStateStore{states: make(map[string]*AuthState)}
+}
+
+// Save 保存 state 对应的登录状态，同时清理已过期的状态.
+func (s *MemoryStateStore) Save(ctx context.Context, state string, as *AuthState) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for k, v := range s.states {
+		if now.After(v.ExpiresAt) {
+			delete(s.states, k)
+		}
+	}
+
+	s.states[state] = as
+	return nil
+}
+
+// Take 取回并删除 state 对应的登录状态.
+func (s *MemoryStateStore) Take(ctx context.Context, state string) (*AuthState, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	as, ok := s.states[state]
+	if !ok {
+		return nil, ErrInvalidState
+	}
+	delete(s.states, state)
+
+	if time.Now().After(as.ExpiresAt) {
+		return nil, ErrInvalidState
+	}
+	return as, nil
+}
+
+// RedisStateStore 是基于 Redis 的登录状态存储，多个实例共享登录状态.
+type RedisStateStore struct {
+	client redis.UniversalClient
+	prefix string
+}
+
+var _ StateStore = (*RedisStateStore)(nil)
+
+// NewRedisStateStore 使用 db.NewRedis 创建的客户端构造 Redis 登录状态存储，prefix 为 Redis 键的前缀.
+func NewRedisStateStore(client redis.UniversalClient, prefix string) *RedisStateStore {
+	return &RedisStateStore{client: client, prefix: prefix}
+}
+
+// Save 保存 state 对应的登录状态，Redis 键在状态过期时自动删除.
+func (s *RedisStateStore) Save(ctx context.Context, state string, as *AuthState) error {
+	data, err := json.Marshal(as)
+	if err != nil {
+		return err
+	}
+	return s.client.Set(ctx, s.prefix+state, data, time.Until(as.ExpiresAt)).Err()
+}
+
+// Take 使用 GETDEL 原子地取回并删除登录状态，同一个 state 的并发回调只有一个能够成功.
+func (s *RedisStateStore) Take(ctx context.Context, state string) (*AuthState, error) {
+	data, err := s.client.GetDel(ctx, s.prefix+state).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, ErrInvalidState
+		}
+		return nil, err
+	}
+
+	var as AuthState
+	if err := json.Unmarshal(data, &as); err != nil {
+		return nil, err
+	}
+	if time.Now().After(as.ExpiresAt) {
+		return nil, ErrInvalidState
+	}
+	return &as, nil
+}
@@ -16,6 +16,7 @@ import (
 	"time"
 
 	"github.com/asaskevich/govalidator"
+	"github.com/golang-jwt/jwt/v4"
 	"github.com/spf13/pflag"
 )
 
@@ -27,6 +28,15 @@ type JWTOptions struct {
 	Expired       time.Duration `json:"expired" mapstructure:"expired"`
 	MaxRefresh    time.Duration `json:"max-refresh" mapstructure:"max-refresh"`
 	SigningMethod string        `json:"signing-method" mapstructure:"signing-method"`
+	// PublicKey is the PEM encoded public key used to verify tokens signed with
+	// an asymmetric signing method (RS*, PS*, ES*, EdDSA).
+	PublicKey string `json:"public-key" mapstructure:"public-key"`
+	// Issuer is stamped into the iss claim and required when verifying tokens.
+	Issuer string `json:"issuer" mapstructure:"issuer"`
+	// Audience is the list of accepted aud values.
+	Audience []string `json:"audience" mapstructure:"audience"`
+	// Leeway is the allowed clock skew when validating exp, nbf and iat.
+	Leeway time.Duration `json:"leeway" mapstructure:"leeway"`
 
 	fullPrefix string
 }
@@ -47,8 +57,29 @@ func NewJWTOptions() *JWTOptions {
 func (s *JWTOptions) Validate() []error {
 	var errs []error
 
-	if !govalidator.StringLength(s.Key, "6", "32") {
-		errs = append(errs, fmt.Errorf("--%s.key must larger than 5 and little than 33", s.fullPrefix))
+	// Only the methods the token package can sign and verify with are accepted.
+	// "none" is registered in jwt but must never be used to issue tokens.
+	method := jwt.GetSigningMethod(s.SigningMethod)
+	switch method.(type) {
+	case *jwt.SigningMethodHMAC, *jwt.SigningMethodRSA, *jwt.SigningMethodRSAPSS,
+		*jwt.SigningMethodECDSA, *jwt.SigningMethodEd25519:
+	default:
+		errs = append(errs, fmt.Errorf("--%s.signing-method %q is not supported", s.fullPrefix, s.SigningMethod))
+		return errs
+	}
+
+	// Asymmetric signing methods use a PEM encoded private key, so the length limit
+	// only applies to HMAC secrets.
+	if _, ok := method.(*jwt.SigningMethodHMAC); ok {
+		if !govalidator.StringLength(s.Key, "6", "32") {
+			errs = append(errs, fmt.Errorf("--%s.key must larger than 5 and little than 33", s.fullPrefix))
+		}
+	} else if s.Key == "" && s.PublicKey == "" {
+		errs = append(errs, fmt.Errorf("--%s.key or --%s.public-key is required for %s", s.fullPrefix, s.fullPrefix, s.SigningMethod))
+	}
+
+	if s.Leeway < 0 {
+		errs = append(errs, fmt.Errorf("--%s.leeway must not be negative", s.fullPrefix))
 	}
 
 	return errs
@@ -67,4 +98,9 @@ func (s *JWTOptions) AddFlags(fs *pflag.FlagSet, fullPrefix string) {
 	fs.DurationVar(&s.MaxRefresh, fullPrefix+".max-refresh", s.MaxRefresh, ""+
 		"This field allows clients to refresh their token until MaxRefresh has passed.")
 	fs.StringVar(&s.SigningMethod, fullPrefix+".signing-method", s.SigningMethod, "JWT token signature method.")
+	fs.StringVar(&s.PublicKey, fullPrefix+".public-key", s.PublicKey, ""+
+		"PEM encoded public key used to verify jwt token signed with an asymmetric signing method.")
+	fs.StringVar(&s.Issuer, fullPrefix+".issuer", s.Issuer, "Issuer stamped into and required in the iss claim of jwt token.")
+	fs.StringSliceVar(&s.Audience, fullPrefix+".audience", s.Audience, "Accepted audiences of jwt token, stamped into the aud claim.")
+	fs.DurationVar(&s.Leeway, fullPrefix+".leeway", s.Leeway, "Allowed clock skew when validating exp, nbf and iat claims.")
 }
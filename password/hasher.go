@@ -0,0 +1,291 @@
+/**FileHeader
+ * @Author: Liangkang Zhang
+ * @Date: 2026/2/21 20:09:14
+ * @LastEditors: Liangkang Zhang
+ * @LastEditTime: 2026/2/21 20:09:14
+ * @Description:
+ * @Copyright: Copyright (©)}) 2026 Liangkang Zhang<lkzhang98@gmail.com>. All rights reserved. Use of this source code is governed by a MIT style license that can be found in the LICENSE file.. All rights reserved.
+ * @Email: lkzhang98@gmail.com
+ * @Repository: https://github.com/geminik12/autostack
+ */
+package password
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/crypto/scrypt"
+)
+
+// 支持的哈希算法标识，与编码后哈希值中的算法标识一致.
+const (
+	AlgorithmBcrypt   = "bcrypt"
+	AlgorithmArgon2id = "argon2id"
+	AlgorithmScrypt   = "scrypt"
+)
+
+// ErrInvalidHash 表示编码后的哈希值格式错误或使用了未注册的算法.
+var ErrInvalidHash = errors.New("invalid password hash")
+
+// Hasher 定义了一种密码哈希算法.
+// 编码后的哈希值是自描述的，包含算法标识、参数和盐值，例如：
+//
+//	$2a$12$...（bcrypt）
+//	$argon2id$v=19$m=65536,t=3,p=2$<salt>$<hash>
+//	$scrypt$ln=15,r=8,p=1$<salt>$<hash>
+type Hasher interface {
+	// Algorithm 返回算法标识.
+	Algorithm() string
+	// Hash 计算密码的哈希值并编码.
+	Hash(password string) (string, error)
+	// Verify 判断密码与编码后的哈希值是否匹配.
+	Verify(password, encoded string) (bool, error)
+	// NeedsRehash 判断哈希值使用的参数是否弱于当前配置，需要重新计算.
+	NeedsRehash(encoded string) bool
+}
+
+// algorithmOf 返回编码后哈希值的算法标识.
+func algorithmOf(encoded string) string {
+	if strings.HasPrefix(encoded, "$2a$") || strings.HasPrefix(encoded, "$2b$") || strings.HasPrefix(encoded, "$2y$") {
+		return AlgorithmBcrypt
+	}
+	if fields := strings.Split(encoded, "$"); len(fields) > 1 && fields[0] == "" {
+		return fields[1]
+	}
+	return ""
+}
+
+// BcryptHasher 使用 bcrypt 计算密码哈希，bcrypt 只使用密码的前 72 个字节.
+type BcryptHasher struct {
+	Cost int
+}
+
+var _ Hasher = (*BcryptHasher)(nil)
+
+// NewBcryptHasher 创建一个使用 cost 作为计算成本的 bcrypt Hasher，cost 为 0 时使用 bcrypt.DefaultCost.
+func NewBcryptHasher(cost int) *BcryptHasher {
+	if cost == 0 {
+		cost = bcrypt.DefaultCost
+	}
+	return &BcryptHasher{Cost: cost}
+}
+
+func (h *BcryptHasher) Algorithm() string { return AlgorithmBcrypt }
+
+func (h *BcryptHasher) Hash(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), h.Cost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+func (h *BcryptHasher) Verify(password, encoded string) (bool, error) {
+	err := bcrypt.CompareHashAndPassword([]byte(encoded), []byte(password))
+	switch {
+	case err == nil:
+		return true, nil
+	case errors.Is(err, bcrypt.ErrMismatchedHashAndPassword):
+		return false, nil
+	default:
+		return false, fmt.Errorf("%w: %v", ErrInvalidHash, err)
+	}
+}
+
+func (h *BcryptHasher) NeedsRehash(encoded string) bool {
+	cost, err := bcrypt.Cost([]byte(encoded))
+	return err != nil || cost < h.Cost
+}
+
+// Argon2idHasher 使用 argon2id 计算密码哈希，参数含义见 RFC 9106.
+type Argon2idHasher struct {
+	// Memory 是使用的内存大小，单位为 KiB.
+	Memory      uint32
+	Iterations  uint32
+	Parallelism uint8
+	SaltLength  uint32
+	KeyLength   uint32
+}
+
+var _ Hasher = (*Argon2idHasher)(nil)
+
+// NewArgon2idHasher 创建一个使用 OWASP 推荐参数（m=64MiB, t=3, p=2）的 argon2id Hasher.
+func NewArgon2idHasher() *Argon2idHasher {
+	return &Argon2idHasher{Memory: 64 * 1024, Iterations: 3, Parallelism: 2, SaltLength: 16, KeyLength: 32}
+}
+
+func (h *Argon2idHasher) Algorithm() string { return AlgorithmArgon2id }
+
+func (h *Argon2idHasher) Hash(password string) (string, error) {
+	salt, err := randomSalt(h.SaltLength)
+	if err != nil {
+		return "", err
+	}
+	key := argon2.IDKey([]byte(password), salt, h.Iterations, h.Memory, h.Parallelism, h.KeyLength)
+
+	return fmt.Sprintf("$%s$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		AlgorithmArgon2id, argon2.Version, h.Memory, h.Iterations, h.Parallelism, encodeBase64(salt), encodeBase64(key)), nil
+}
+
+func (h *Argon2idHasher) Verify(password, encoded string) (bool, error) {
+	p, err := parseArgon2id(encoded)
+	if err != nil {
+		return false, err
+	}
+	key := argon2.IDKey([]byte(password), p.salt, p.iterations, p.memory, p.parallelism, uint32(len(p.key)))
+	return subtle.ConstantTimeCompare(key, p.key) == 1, nil
+}
+
+func (h *Argon2idHasher) NeedsRehash(encoded string) bool {
+	p, err := parseArgon2id(encoded)
+	return err != nil || p.memory < h.Memory || p.iterations < h.Iterations || p.parallelism < h.Parallelism ||
+		uint32(len(p.salt)) < h.SaltLength || uint32(len(p.key)) < h.KeyLength
+}
+
+// argon2idParams 是从编码后的哈希值中解析出的 argon2id 参数.
+type argon2idParams struct {
+	memory      uint32
+	iterations  uint32
+	parallelism uint8
+	salt        []byte
+	key         []byte
+}
+
+// parseArgon2id 解析 $argon2id$v=19$m=..,t=..,p=..$salt$hash 格式的哈希值.
+func parseArgon2id(encoded string) (*argon2idParams, error) {
+	fields := strings.Split(encoded, "$")
+	if len(fields) != 6 || fields[1] != AlgorithmArgon2id {
+		return nil, ErrInvalidHash
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(fields[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return nil, ErrInvalidHash
+	}
+
+	p := &argon2idParams{}
+	if _, err := fmt.Sscanf(fields[3], "m=%d,t=%d,p=%d", &p.memory, &p.iterations, &p.parallelism); err != nil {
+		return nil, ErrInvalidHash
+	}
+	// 非法参数会使 argon2.IDKey panic，t 和 p 至少为 1，m 至少为 8*p（单位 KiB）
+	if p.iterations < 1 || p.parallelism < 1 || p.memory < 8*uint32(p.parallelism) {
+		return nil, ErrInvalidHash
+	}
+
+	var err error
+	if p.salt, err = decodeBase64(fields[4]); err != nil {
+		return nil, ErrInvalidHash
+	}
+	if p.key, err = decodeBase64(fields[5]); err != nil || len(p.key) == 0 {
+		return nil, ErrInvalidHash
+	}
+
+	return p, nil
+}
+
+// ScryptHasher 使用 scrypt 计算密码哈希，N = 2^LogN.
+type ScryptHasher struct {
+	LogN       uint8
+	R          int
+	P          int
+	SaltLength uint32
+	KeyLength  int
+}
+
+var _ Hasher = (*ScryptHasher)(nil)
+
+// NewScryptHasher 创建一个使用 N=2^15, r=8, p=1 的 scrypt Hasher.
+func NewScryptHasher() *ScryptHasher {
+	return &ScryptHasher{LogN: 15, R: 8, P: 1, SaltLength: 16, KeyLength: 32}
+}
+
+func (h *ScryptHasher) Algorithm() string { return AlgorithmScrypt }
+
+func (h *ScryptHasher) Hash(password string) (string, error) {
+	salt, err := randomSalt(h.SaltLength)
+	if err != nil {
+		return "", err
+	}
+	key, err := scrypt.Key([]byte(password), salt, 1<<h.LogN, h.R, h.P, h.KeyLength)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("$%s$ln=%d,r=%d,p=%d$%s$%s",
+		AlgorithmScrypt, h.LogN, h.R, h.P, encodeBase64(salt), encodeBase64(key)), nil
+}
+
+func (h *ScryptHasher) Verify(password, encoded string) (bool, error) {
+	p, err := parseScrypt(encoded)
+	if err != nil {
+		return false, err
+	}
+	key, err := scrypt.Key([]byte(password), p.salt, 1<<p.logN, p.r, p.p, len(p.key))
+	if err != nil {
+		return false, fmt.Errorf("%w: %v", ErrInvalidHash, err)
+	}
+	return subtle.ConstantTimeCompare(key, p.key) == 1, nil
+}
+
+func (h *ScryptHasher) NeedsRehash(encoded string) bool {
+	p, err := parseScrypt(encoded)
+	return err != nil || p.logN < h.LogN || p.r < h.R || p.p < h.P ||
+		uint32(len(p.salt)) < h.SaltLength || len(p.key) < h.KeyLength
+}
+
+// scryptParams 是从编码后的哈希值中解析出的 scrypt 参数.
+type scryptParams struct {
+	logN uint8
+	r    int
+	p    int
+	salt []byte
+	key  []byte
+}
+
+// parseScrypt 解析 $scrypt$ln=..,r=..,p=..$salt$hash 格式的哈希值.
+func parseScrypt(encoded string) (*scryptParams, error) {
+	fields := strings.Split(encoded, "$")
+	if len(fields) != 5 || fields[1] != AlgorithmScrypt {
+		return nil, ErrInvalidHash
+	}
+
+	p := &scryptParams{}
+	if _, err := fmt.Sscanf(fields[2], "ln=%d,r=%d,p=%d", &p.logN, &p.r, &p.p); err != nil || p.logN == 0 || p.logN > 30 {
+		return nil, ErrInvalidHash
+	}
+
+	var err error
+	if p.salt, err = decodeBase64(fields[3]); err != nil {
+		return nil, ErrInvalidHash
+	}
+	if p.key, err = decodeBase64(fields[4]); err != nil || len(p.key) == 0 {
+		return nil, ErrInvalidHash
+	}
+
+	return p, nil
+}
+
+// randomSalt 生成 n 个字节的随机盐值.
+func randomSalt(n uint32) ([]byte, error) {
+	salt := make([]byte, n)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	return salt, nil
+}
+
+// encodeBase64 使用 PHC 字符串格式约定的无填充标准 base64 编码.
+func encodeBase64(b []byte) string {
+	return base64.RawStdEncoding.EncodeToString(b)
+}
+
+// decodeBase64 解码无填充标准 base64.
+func decodeBase64(s string) ([]byte, error) {
+	return base64.RawStdEncoding.DecodeString(s)
+}
@@ -0,0 +1,145 @@
+/**FileHeader
+ * @Author: Liangkang Zhang
+ * @Date: 2026/2/21 21:30:18
+ * @LastEditors: Liangkang Zhang
+ * @LastEditTime: 2026/2/21 21:30:18
+ * @Description:
+ * @Copyright: Copyright (©)}) 2026 Liangkang Zhang<lkzhang98@gmail.com>. All rights reserved. Use of this source code is governed by a MIT style license that can be found in the LICENSE file.. All rights reserved.
+ * @Email: lkzhang98@gmail.com
+ * @Repository: https://github.com/geminik12/autostack
+ */
+package password
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/geminik12/autostack/core"
+	"github.com/geminik12/autostack/errorsx"
+	"github.com/geminik12/autostack/log"
+	"github.com/geminik12/autostack/model"
+	"github.com/geminik12/autostack/store"
+	"github.com/geminik12/autostack/token"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// ErrInvalidCredentials 表示用户名或密码错误，不区分用户不存在和密码错误.
+var ErrInvalidCredentials = errors.New("invalid username or password")
+
+// CredentialStore 定义了登录时读取用户和更新密码哈希的接口.
+type CredentialStore interface {
+	// GetUserByUsername 根据用户名获取用户，用户不存在时返回 store.ErrUserNotFound 或 gorm.ErrRecordNotFound.
+	GetUserByUsername(ctx context.Context, username string) (*model.UserM, error)
+	// UpdatePassword 更新用户的密码哈希，用于验证通过后升级哈希算法或参数.
+	UpdatePassword(ctx context.Context, userID string, encoded string) error
+}
+
+// Authenticate 使用用户名和密码进行认证，返回认证通过的用户.
+// 用户不存在或密码错误时都返回 ErrInvalidCredentials，读取用户的其他错误（例如数据库不可用）原样返回.
+// 哈希值需要升级时会重新计算并通过 credentials 保存.
+func (m *Manager) Authenticate(ctx context.Context, credentials CredentialStore, username, password string) (*model.UserM, error) {
+	user, err := credentials.GetUserByUsername(ctx, username)
+	if err != nil {
+		if !isUserNotFound(err) {
+			return nil, err
+		}
+		// 用户不存在时仍然计算一次哈希，避免通过响应时间判断用户名是否存在
+		_, _ = m.Verify(password, m.dummyHash())
+		return nil, ErrInvalidCredentials
+	}
+
+	ok, rehashed, err := m.VerifyAndRehash(password, user.Password)
+	if err != nil {
+		log.W(ctx).Errorw(err, "Failed to verify password", "userID", user.UserID)
+		return nil, ErrInvalidCredentials
+	}
+	if !ok {
+		return nil, ErrInvalidCredentials
+	}
+
+	if rehashed != "" {
+		// 升级哈希失败不影响本次登录，下次登录时会再次尝试
+		if err := credentials.UpdatePassword(ctx, user.UserID, rehashed); err != nil {
+			log.W(ctx).Errorw(err, "Failed to upgrade password hash", "userID", user.UserID)
+		} else {
+			user.Password = rehashed
+		}
+	}
+
+	return user, nil
+}
+
+// isUserNotFound 判断 CredentialStore 返回的错误是否表示用户不存在.
+func isUserNotFound(err error) bool {
+	return errors.Is(err, store.ErrUserNotFound) || errors.Is(err, gorm.ErrRecordNotFound)
+}
+
+// dummyHash 返回用于防止时序攻击的哈希值，使用当前算法计算.
+func (m *Manager) dummyHash() string {
+	m.dummyOnce.Do(func() {
+		m.dummy, _ = m.hasher.Hash("autostack-dummy-password")
+	})
+	return m.dummy
+}
+
+// LoginRequest 是登录接口的请求参数.
+type LoginRequest struct {
+	Username string `json:"username" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+// LoginResponse 是登录接口的响应.
+type LoginResponse struct {
+	Token    string    `json:"token"`
+	ExpireAt time.Time `json:"expireAt"`
+}
+
+// LoginOption 用于配置登录处理函数.
+type LoginOption func(*loginOptions)
+
+// loginOptions 是登录处理函数的配置.
+type loginOptions struct {
+	manager *token.Manager
+}
+
+// WithTokenManager 设置签发 token 使用的 token.Manager，默认使用 token 包的默认 Manager.
+func WithTokenManager(manager *token.Manager) LoginOption {
+	return func(o *loginOptions) {
+		o.manager = manager
+	}
+}
+
+// LoginHandler 返回一个 Gin 处理函数，验证请求体中的用户名和密码，认证通过后使用用户 ID 签发 token.
+// 签发的 token 可以直接被 AuthnMiddleware 解析. 用户名或密码错误时返回 401，读取用户失败等服务端错误返回 500.
+func LoginHandler(m *Manager, credentials CredentialStore, opts ...LoginOption) gin.HandlerFunc {
+	o := &loginOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return func(c *gin.Context) {
+		core.HandleJSONRequest(c, func(ctx context.Context, rq *LoginRequest) (*LoginResponse, error) {
+			user, err := m.Authenticate(ctx, credentials, rq.Username, rq.Password)
+			if err != nil {
+				if errors.Is(err, ErrInvalidCredentials) {
+					return nil, errorsx.ErrUnauthenticated.WithMessage("%s", err.Error())
+				}
+				return nil, errorsx.ErrInternal.WithMessage("%s", err.Error())
+			}
+
+			manager := o.manager
+			if manager == nil {
+				manager = token.Default()
+			}
+
+			tokenString, expireAt, err := manager.Sign(user.UserID)
+			if err != nil {
+				return nil, errorsx.ErrSignToken.WithMessage("%s", err.Error())
+			}
+
+			return &LoginResponse{Token: tokenString, ExpireAt: expireAt}, nil
+		})
+	}
+}
@@ -0,0 +1,134 @@
+/**FileHeader
+ * @Author: Liangkang Zhang
+ * @Date: 2026/2/21 21:02:55
+ * @LastEditors: Liangkang Zhang
+ * @LastEditTime: 2026/2/21 21:02:55
+ * @Description:
+ * @Copyright: Copyright (©)}) 2026 Liangkang Zhang<lkzhang98@gmail.com>. All rights reserved. Use of this source code is governed by a MIT style license that can be found in the LICENSE file.. All rights reserved.
+ * @Email: lkzhang98@gmail.com
+ * @Repository: https://github.com/geminik12/autostack
+ */
+package password
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Option 用于配置 Manager.
+type Option func(*Manager)
+
+// WithHasher 设置计算新密码哈希使用的算法，默认为 argon2id.
+// 其他已注册算法的哈希值仍然可以验证，验证通过后 VerifyAndRehash 会使用该算法重新计算.
+func WithHasher(hasher Hasher) Option {
+	return func(m *Manager) {
+		m.hasher = hasher
+		m.hashers[hasher.Algorithm()] = hasher
+	}
+}
+
+// WithHashers 注册只用于验证的算法，例如使用自定义参数的 bcrypt，同一算法只保留最后注册的 Hasher.
+func WithHashers(hashers ...Hasher) Option {
+	return func(m *Manager) {
+		for _, h := range hashers {
+			m.hashers[h.Algorithm()] = h
+		}
+	}
+}
+
+// WithPolicy 设置密码策略，默认为 DefaultPolicy.
+func WithPolicy(policy Policy) Option {
+	return func(m *Manager) {
+		m.policy = policy
+	}
+}
+
+// Manager 负责密码的哈希、验证和策略校验.
+type Manager struct {
+	hasher  Hasher
+	hashers map[string]Hasher
+	policy  Policy
+
+	// dummy 是用户不存在时用于验证的哈希值，避免通过响应时间判断用户名是否存在
+	dummyOnce sync.Once
+	dummy     string
+}
+
+// NewManager 创建一个 Manager，默认使用 argon2id 计算新密码的哈希，同时可以验证 bcrypt 和 scrypt 的哈希值.
+func NewManager(opts ...Option) *Manager {
+	argon2id := NewArgon2idHasher()
+	m := &Manager{
+		hasher: argon2id,
+		hashers: map[string]Hasher{
+			AlgorithmArgon2id: argon2id,
+			AlgorithmBcrypt:   NewBcryptHasher(0),
+			AlgorithmScrypt:   NewScryptHasher(),
+		},
+		policy: DefaultPolicy(),
+	}
+
+	for _, opt := range opts {
+		opt(m)
+	}
+	// WithHashers 注册的同名算法不应覆盖 WithHasher 指定的算法
+	m.hashers[m.hasher.Algorithm()] = m.hasher
+
+	return m
+}
+
+// Policy 返回密码策略.
+func (m *Manager) Policy() Policy {
+	return m.policy
+}
+
+// Hash 校验密码是否满足策略，并使用当前算法计算哈希值，结果可以直接保存到 model.UserM.Password.
+func (m *Manager) Hash(password string) (string, error) {
+	if err := m.policy.Validate(password); err != nil {
+		return "", err
+	}
+	return m.hasher.Hash(password)
+}
+
+// Verify 判断密码与编码后的哈希值是否匹配，哈希值可以使用任意已注册的算法.
+func (m *Manager) Verify(password, encoded string) (bool, error) {
+	hasher, err := m.hasherOf(encoded)
+	if err != nil {
+		return false, err
+	}
+	return hasher.Verify(password, encoded)
+}
+
+// VerifyAndRehash 验证密码，验证通过且哈希值使用的算法或参数已经过时时返回重新计算的哈希值，调用方应保存新的哈希值.
+// 哈希值无需更新时 rehashed 为空. 重新计算不校验密码策略，避免策略升级后已有用户无法登录.
+func (m *Manager) VerifyAndRehash(password, encoded string) (ok bool, rehashed string, err error) {
+	hasher, err := m.hasherOf(encoded)
+	if err != nil {
+		return false, "", err
+	}
+
+	if ok, err = hasher.Verify(password, encoded); err != nil || !ok {
+		return false, "", err
+	}
+
+	if hasher.Algorithm() != m.hasher.Algorithm() || m.hasher.NeedsRehash(encoded) {
+		if rehashed, err = m.hasher.Hash(password); err != nil {
+			return true, "", err
+		}
+	}
+
+	return true, rehashed, nil
+}
+
+// NeedsRehash 判断哈希值是否需要使用当前的算法和参数重新计算.
+func (m *Manager) NeedsRehash(encoded string) bool {
+	return algorithmOf(encoded) != m.hasher.Algorithm() || m.hasher.NeedsRehash(encoded)
+}
+
+// hasherOf 返回编码后哈希值使用的算法.
+func (m *Manager) hasherOf(encoded string) (Hasher, error) {
+	hasher, ok := m.hashers[algorithmOf(encoded)]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown algorithm", ErrInvalidHash)
+	}
+	return hasher, nil
+}
@@ -0,0 +1,105 @@
+/**FileHeader
+ * @Author: Liangkang Zhang
+ * @Date: 2026/2/21 20:41:30
+ * @LastEditors: Liangkang Zhang
+ * @LastEditTime: 2026/2/21 20:41:30
+ * @Description:
+ * @Copyright: Copyright (©)}) 2026 Liangkang Zhang<lkzhang98@gmail.com>. All rights reserved. Use of this source code is governed by a MIT style license that can be found in the LICENSE file.. All rights reserved.
+ * @Email: lkzhang98@gmail.com
+ * @Repository: https://github.com/geminik12/autostack
+ */
+package password
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// 密码策略相关的预定义错误
+var (
+	ErrPasswordTooShort  = errors.New("password is too short")
+	ErrPasswordTooLong   = errors.New("password is too long")
+	ErrPasswordTooWeak   = errors.New("password does not meet complexity requirements")
+	ErrPasswordForbidden = errors.New("password is too common")
+)
+
+// Policy 是密码策略，在设置密码时校验.
+type Policy struct {
+	// MinLength 是密码的最小长度（字符数）.
+	MinLength int
+	// MaxLength 是密码的最大长度（字节数），为 0 时不限制. bcrypt 只使用密码的前 72 个字节.
+	MaxLength int
+	// RequireLetter 要求至少包含一个字母，RequireUpper 和 RequireLower 分别要求包含大写和小写字母.
+	RequireLetter bool
+	RequireUpper  bool
+	RequireLower  bool
+	RequireDigit  bool
+	RequireSymbol bool
+	// Forbidden 是禁止使用的常见密码，比较时不区分大小写.
+	Forbidden []string
+}
+
+// DefaultPolicy 返回默认的密码策略：长度为 8 到 72，至少包含字母和数字.
+func DefaultPolicy() Policy {
+	return Policy{
+		MinLength:     8,
+		MaxLength:     72,
+		RequireLetter: true,
+		RequireDigit:  true,
+	}
+}
+
+// Validate 校验密码是否满足策略.
+func (p Policy) Validate(password string) error {
+	if utf8.RuneCountInString(password) < p.MinLength {
+		return fmt.Errorf("%w: at least %d characters", ErrPasswordTooShort, p.MinLength)
+	}
+	if p.MaxLength > 0 && len(password) > p.MaxLength {
+		return fmt.Errorf("%w: at most %d bytes", ErrPasswordTooLong, p.MaxLength)
+	}
+
+	var letter, upper, lower, digit, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsLetter(r):
+			letter = true
+			upper = upper || unicode.IsUpper(r)
+			lower = lower || unicode.IsLower(r)
+		case unicode.IsDigit(r):
+			digit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r):
+			symbol = true
+		}
+	}
+
+	var missing []string
+	if p.RequireLetter && !letter {
+		missing = append(missing, "a letter")
+	}
+	if p.RequireUpper && !upper {
+		missing = append(missing, "an uppercase letter")
+	}
+	if p.RequireLower && !lower {
+		missing = append(missing, "a lowercase letter")
+	}
+	if p.RequireDigit && !digit {
+		missing = append(missing, "a digit")
+	}
+	if p.RequireSymbol && !symbol {
+		missing = append(missing, "a symbol")
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("%w: must contain %s", ErrPasswordTooWeak, strings.Join(missing, ", "))
+	}
+
+	for _, forbidden := range p.Forbidden {
+		if strings.EqualFold(password, forbidden) {
+			return ErrPasswordForbidden
+		}
+	}
+
+	return nil
+}
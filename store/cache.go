@@ -0,0 +1,331 @@
+/**FileHeader
+ * @Author: Liangkang Zhang
+ * @Date: 2026/2/22 21:04:09
+ * @LastEditors: Liangkang Zhang
+ * @LastEditTime: 2026/2/22 21:04:09
+ * @Description:
+ * @Copyright: Copyright (©)}) 2026 Liangkang Zhang<lkzhang98@gmail.com>. All rights reserved. Use of this source code is governed by a MIT style license that can be found in the LICENSE file.. All rights reserved.
+ * @Email: lkzhang98@gmail.com
+ * @Repository: https://github.com/geminik12/autostack
+ */
+package store
+
+import (
+	"container/list"
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/geminik12/autostack/log"
+	"github.com/geminik12/autostack/model"
+	redis "github.com/redis/go-redis/v9"
+)
+
+// UserCache 定义了用户缓存的接口，键由 CachedUserStore 生成.
+type UserCache interface {
+	// Get 返回缓存的用户，未命中时返回 nil.
+	Get(ctx context.Context, key string) (*model.UserM, error)
+	// Set 缓存用户.
+	Set(ctx context.Context, key string, user *model.UserM) error
+	// Delete 删除缓存的用户.
+	Delete(ctx context.Context, keys ...string) error
+}
+
+// 缓存键的前缀，同一个用户会分别按用户 ID 和手机号缓存.
+const (
+	cacheKeyUserID = "user:id:"
+	cacheKeyPhone  = "user:phone:"
+)
+
+// CachedUserStore 是带有读穿透缓存的用户存储.
+// GetUser 和 GetUserByPhone 优先从缓存读取，未命中时读取底层存储并写入缓存；更新和删除用户后会删除该用户的全部缓存.
+// 缓存中不保存密码哈希，这两个方法返回的用户 Password 为空；GetUserByUsername 用于登录校验密码，始终读取底层存储.
+// 其他实例更新用户后本实例的进程内缓存不会失效，多实例部署时应使用 RedisUserCache，或为 LRUUserCache 设置较短的有效期.
+type CachedUserStore struct {
+	UserStore
+
+	cache UserCache
+
+	// generation 在每次修改用户后递增，读穿透期间发生过修改时不写入缓存，避免并发读取把修改前的用户重新写回缓存.
+	// setMu 保证写入缓存前的检查和写入不会与递增交错.
+	generation atomic.Uint64
+	setMu      sync.RWMutex
+}
+
+var _ UserStore = (*CachedUserStore)(nil)
+
+// NewCachedUserStore 创建一个使用 cache 缓存 store 中用户的存储.
+func NewCachedUserStore(store UserStore, cache UserCache) *CachedUserStore {
+	return &CachedUserStore{UserStore: store, cache: cache}
+}
+
+// GetUser 根据用户 ID 获取用户，优先从缓存读取.
+func (s *CachedUserStore) GetUser(ctx context.Context, userID string) (*model.UserM, error) {
+	return s.readThrough(ctx, cacheKeyUserID+userID, func() (*model.UserM, error) {
+		return s.UserStore.GetUser(ctx, userID)
+	})
+}
+
+// GetUserByUsername 根据用户名获取用户，包含密码哈希.
+// 用户名查询是 password.Authenticate 的登录路径，需要最新的密码哈希，因此不经过缓存.
+func (s *CachedUserStore) GetUserByUsername(ctx context.Context, username string) (*model.UserM, error) {
+	return s.UserStore.GetUserByUsername(ctx, username)
+}
+
+// GetUserByPhone 根据手机号获取用户，优先从缓存读取.
+func (s *CachedUserStore) GetUserByPhone(ctx context.Context, phone string) (*model.UserM, error) {
+	return s.readThrough(ctx, cacheKeyPhone+phone, func() (*model.UserM, error) {
+		return s.UserStore.GetUserByPhone(ctx, phone)
+	})
+}
+
+// UpdateUser 更新用户并删除该用户更新前后的全部缓存.
+// 从缓存读取的用户不包含密码哈希，user.Password 为空时保留原来的密码哈希.
+func (s *CachedUserStore) UpdateUser(ctx context.Context, user *model.UserM) error {
+	return s.invalidateAround(ctx, user.UserID, func(old *model.UserM) error {
+		if user.Password == "" && old != nil {
+			updated := *user
+			updated.Password = old.Password
+			return s.UserStore.UpdateUser(ctx, &updated)
+		}
+		return s.UserStore.UpdateUser(ctx, user)
+	}, user)
+}
+
+// UpdatePassword 更新用户的密码哈希并删除该用户的全部缓存.
+func (s *CachedUserStore) UpdatePassword(ctx context.Context, userID string, encoded string) error {
+	return s.invalidateAround(ctx, userID, func(*model.UserM) error {
+		return s.UserStore.UpdatePassword(ctx, userID, encoded)
+	})
+}
+
+// DeleteUser 删除用户并删除该用户的全部缓存.
+func (s *CachedUserStore) DeleteUser(ctx context.Context, userID string) error {
+	return s.invalidateAround(ctx, userID, func(*model.UserM) error {
+		return s.UserStore.DeleteUser(ctx, userID)
+	})
+}
+
+// readThrough 从缓存读取用户，未命中时调用 load 读取并写入缓存，返回的用户不包含密码哈希.
+// 缓存读写失败时直接使用底层存储，不影响请求.
+func (s *CachedUserStore) readThrough(ctx context.Context, key string, load func() (*model.UserM, error)) (*model.UserM, error) {
+	user, err := s.cache.Get(ctx, key)
+	if err != nil {
+		log.W(ctx).Errorw(err, "Failed to read user from cache", "key", key)
+	}
+	if user != nil {
+		return user, nil
+	}
+
+	generation := s.generation.Load()
+	loaded, err := load()
+	if err != nil {
+		return nil, err
+	}
+
+	cached := *loaded
+	cached.Password = ""
+
+	// 读取期间用户被修改时 load 的结果可能已经过期，本次不写入缓存
+	s.setMu.RLock()
+	defer s.setMu.RUnlock()
+	if s.generation.Load() == generation {
+		if err := s.cache.Set(ctx, key, &cached); err != nil {
+			log.W(ctx).Errorw(err, "Failed to write user to cache", "key", key)
+		}
+	}
+
+	return &cached, nil
+}
+
+// invalidateAround 执行修改操作 mutate，并删除用户修改前后的全部缓存.
+// 修改前读取底层存储中的用户传给 mutate，并用于删除旧手机号对应的缓存；用户不存在时 old 为 nil.
+// 缓存在修改完成之后删除，删除前递增 generation，使修改前开始的读穿透不再写入缓存.
+func (s *CachedUserStore) invalidateAround(ctx context.Context, userID string, mutate func(old *model.UserM) error, updated ...*model.UserM) error {
+	users := updated
+	old, err := s.UserStore.GetUser(ctx, userID)
+	if err == nil {
+		users = append(users, old)
+	} else {
+		old = nil
+	}
+
+	err = mutate(old)
+
+	s.setMu.Lock()
+	s.generation.Add(1)
+	s.setMu.Unlock()
+
+	// 无论修改是否成功都删除缓存，缓存最多多一次未命中
+	keys := []string{cacheKeyUserID + userID}
+	for _, user := range users {
+		keys = append(keys, cacheKeyPhone+user.Phone)
+	}
+	if err := s.cache.Delete(ctx, keys...); err != nil {
+		log.W(ctx).Errorw(err, "Failed to invalidate user cache", "userID", userID)
+	}
+
+	return err
+}
+
+// LRUUserCache 是进程内的 LRU 用户缓存，缓存项超过有效期后失效.
+type LRUUserCache struct {
+	size int
+	ttl  time.Duration
+
+	mu    sync.Mutex
+	ll    *list.List
+	items map[string]*list.Element
+}
+
+var _ UserCache = (*LRUUserCache)(nil)
+
+// lruEntry 是一个缓存项.
+type lruEntry struct {
+	key      string
+	user     model.UserM
+	expireAt time.Time
+}
+
+// NewLRUUserCache 创建一个最多缓存 size 个用户、每个用户保留 ttl 的进程内缓存.
+func NewLRUUserCache(size int, ttl time.Duration) *LRUUserCache {
+	return &LRUUserCache{
+		size:  size,
+		ttl:   ttl,
+		ll:    list.New(),
+		items: make(map[string]*list.Element),
+	}
+}
+
+// Get 返回缓存的用户副本.
+func (c *LRUUserCache) Get(ctx context.Context, key string) (*model.UserM, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.items[key]
+	if !ok {
+		return nil, nil
+	}
+
+	entry := elem.Value.(*lruEntry)
+	if time.Now().After(entry.expireAt) {
+		c.removeElement(elem)
+		return nil, nil
+	}
+	c.ll.MoveToFront(elem)
+
+	user := entry.user
+	return &user, nil
+}
+
+// Set 缓存用户的副本，超出容量时淘汰最久未使用的缓存项.
+func (c *LRUUserCache) Set(ctx context.Context, key string, user *model.UserM) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry := &lruEntry{key: key, user: *user, expireAt: time.Now().Add(c.ttl)}
+	if elem, ok := c.items[key]; ok {
+		elem.Value = entry
+		c.ll.MoveToFront(elem)
+		return nil
+	}
+
+	c.items[key] = c.ll.PushFront(entry)
+	for c.ll.Len() > c.size {
+		c.removeElement(c.ll.Back())
+	}
+
+	return nil
+}
+
+// Delete 删除缓存的用户.
+func (c *LRUUserCache) Delete(ctx context.Context, keys ...string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, key := range keys {
+		if elem, ok := c.items[key]; ok {
+			c.removeElement(elem)
+		}
+	}
+
+	return nil
+}
+
+// removeElement 删除一个缓存项，调用方需持有锁.
+func (c *LRUUserCache) removeElement(elem *list.Element) {
+	c.ll.Remove(elem)
+	delete(c.items, elem.Value.(*lruEntry).key)
+}
+
+// RedisUserCache 是基于 Redis 的用户缓存，用户以 JSON 格式保存，多个实例共享缓存.
+type RedisUserCache struct {
+	client redis.UniversalClient
+	prefix string
+	ttl    time.Duration
+}
+
+var _ UserCache = (*RedisUserCache)(nil)
+
+// RedisCacheOption 用于配置 RedisUserCache.
+type RedisCacheOption func(*RedisUserCache)
+
+// WithKeyPrefix 设置 Redis 键的前缀，用于多个服务共用一个 Redis 时隔离缓存.
+func WithKeyPrefix(prefix string) RedisCacheOption {
+	return func(c *RedisUserCache) {
+		c.prefix = prefix
+	}
+}
+
+// NewRedisUserCache 使用 db.NewRedis 创建的客户端构造 Redis 用户缓存，每个用户保留 ttl.
+func NewRedisUserCache(client redis.UniversalClient, ttl time.Duration, opts ...RedisCacheOption) *RedisUserCache {
+	c := &RedisUserCache{client: client, ttl: ttl}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Get 返回缓存的用户.
+func (c *RedisUserCache) Get(ctx context.Context, key string) (*model.UserM, error) {
+	data, err := c.client.Get(ctx, c.prefix+key).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var user model.UserM
+	if err := json.Unmarshal(data, &user); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// Set 缓存用户.
+func (c *RedisUserCache) Set(ctx context.Context, key string, user *model.UserM) error {
+	data, err := json.Marshal(user)
+	if err != nil {
+		return err
+	}
+	return c.client.Set(ctx, c.prefix+key, data, c.ttl).Err()
+}
+
+// Delete 删除缓存的用户，逐个删除以兼容 Redis Cluster 中不同 slot 的键.
+func (c *RedisUserCache) Delete(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	_, err := c.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, key := range keys {
+			pipe.Del(ctx, c.prefix+key)
+		}
+		return nil
+	})
+	return err
+}
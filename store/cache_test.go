@@ -0,0 +1,106 @@
+package store
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/geminik12/autostack/model"
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+)
+
+// hookedUserStore 在 GetUser 读取到用户之后、返回之前调用一次 afterLoad，用于模拟并发修改.
+type hookedUserStore struct {
+	UserStore
+	afterLoad func()
+}
+
+func (s *hookedUserStore) GetUser(ctx context.Context, userID string) (*model.UserM, error) {
+	user, err := s.UserStore.GetUser(ctx, userID)
+	if hook := s.afterLoad; hook != nil {
+		s.afterLoad = nil
+		hook()
+	}
+	return user, err
+}
+
+// newTestUserStore 创建一个包含用户 user-1 的 SQLite 用户存储.
+func newTestUserStore(t *testing.T) *GormUserStore {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "user.db")), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	if err := db.AutoMigrate(&model.UserM{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	users := NewGormUserStore(db)
+	user := &model.UserM{UserID: "user-1", Username: "alice", Password: "hash", Nickname: "old", Phone: "100"}
+	if err := users.CreateUser(context.Background(), user); err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+	return users
+}
+
+func TestCachedUserStoreSkipsStaleSet(t *testing.T) {
+	ctx := context.Background()
+	backing := &hookedUserStore{UserStore: newTestUserStore(t)}
+	cache := NewLRUUserCache(16, time.Hour)
+	cached := NewCachedUserStore(backing, cache)
+
+	// 读穿透读取到旧用户之后，另一个请求完成了更新
+	backing.afterLoad = func() {
+		if err := cached.UpdateUser(ctx, &model.UserM{UserID: "user-1", Username: "alice", Nickname: "new", Phone: "100"}); err != nil {
+			t.Errorf("UpdateUser: %v", err)
+		}
+	}
+	if _, err := cached.GetUser(ctx, "user-1"); err != nil {
+		t.Fatalf("GetUser: %v", err)
+	}
+
+	if user, _ := cache.Get(ctx, cacheKeyUserID+"user-1"); user != nil {
+		t.Fatalf("stale user cached: %+v", user)
+	}
+	user, err := cached.GetUser(ctx, "user-1")
+	if err != nil {
+		t.Fatalf("GetUser: %v", err)
+	}
+	if user.Nickname != "new" {
+		t.Fatalf("Nickname = %q, want %q", user.Nickname, "new")
+	}
+}
+
+func TestCachedUserStorePassword(t *testing.T) {
+	ctx := context.Background()
+	cache := NewLRUUserCache(16, time.Hour)
+	cached := NewCachedUserStore(newTestUserStore(t), cache)
+
+	user, err := cached.GetUser(ctx, "user-1")
+	if err != nil {
+		t.Fatalf("GetUser: %v", err)
+	}
+	if user.Password != "" {
+		t.Fatalf("GetUser returned password %q", user.Password)
+	}
+	if hit, _ := cache.Get(ctx, cacheKeyUserID+"user-1"); hit == nil || hit.Password != "" {
+		t.Fatalf("cached user = %+v, want cached without password", hit)
+	}
+
+	// 更新从缓存读取的用户时保留原来的密码哈希
+	user.Nickname = "new"
+	if err := cached.UpdateUser(ctx, user); err != nil {
+		t.Fatalf("UpdateUser: %v", err)
+	}
+
+	byName, err := cached.GetUserByUsername(ctx, "alice")
+	if err != nil {
+		t.Fatalf("GetUserByUsername: %v", err)
+	}
+	if byName.Password != "hash" || byName.Nickname != "new" {
+		t.Fatalf("GetUserByUsername = %+v, want password kept and nickname updated", byName)
+	}
+}
@@ -0,0 +1,138 @@
+/**FileHeader
+ * @Author: Liangkang Zhang
+ * @Date: 2026/2/22 20:18:42
+ * @LastEditors: Liangkang Zhang
+ * @LastEditTime: 2026/2/22 20:18:42
+ * @Description:
+ * @Copyright: Copyright (©)}) 2026 Liangkang Zhang<lkzhang98@gmail.com>. All rights reserved. Use of this source code is governed by a MIT style license that can be found in the LICENSE file.. All rights reserved.
+ * @Email: lkzhang98@gmail.com
+ * @Repository: https://github.com/geminik12/autostack
+ */
+package store
+
+import (
+	"context"
+	"errors"
+
+	"github.com/geminik12/autostack/model"
+	"gorm.io/gorm"
+)
+
+// ErrUserNotFound 表示用户不存在或已被删除.
+var ErrUserNotFound = errors.New("user not found")
+
+// UserStore 定义了 model.UserM 的存储接口.
+// UserStore 实现了 Gin 认证中间件的 UserRetriever 接口和 password.CredentialStore 接口.
+type UserStore interface {
+	// GetUser 根据用户 ID 获取用户，不存在时返回 ErrUserNotFound.
+	GetUser(ctx context.Context, userID string) (*model.UserM, error)
+	// GetUserByUsername 根据用户名获取用户，不存在时返回 ErrUserNotFound.
+	GetUserByUsername(ctx context.Context, username string) (*model.UserM, error)
+	// GetUserByPhone 根据手机号获取用户，不存在时返回 ErrUserNotFound.
+	GetUserByPhone(ctx context.Context, phone string) (*model.UserM, error)
+	// CreateUser 创建用户.
+	CreateUser(ctx context.Context, user *model.UserM) error
+	// UpdateUser 根据 user.UserID 更新用户的全部字段.
+	UpdateUser(ctx context.Context, user *model.UserM) error
+	// UpdatePassword 更新用户的密码哈希.
+	UpdatePassword(ctx context.Context, userID string, encoded string) error
+	// DeleteUser 软删除用户，删除后无法再通过 Get* 方法获取，用户名和手机号可以被新用户重新使用.
+	DeleteUser(ctx context.Context, userID string) error
+	// ListUsers 按创建顺序分页返回用户，以及用户总数.
+	ListUsers(ctx context.Context, offset, limit int) (int64, []*model.UserM, error)
+}
+
+// GormUserStore 是基于 gorm 的用户存储，用户保存在 user 表.
+type GormUserStore struct {
+	db *gorm.DB
+}
+
+var _ UserStore = (*GormUserStore)(nil)
+
+// NewGormUserStore 创建一个基于 gorm 的用户存储.
+func NewGormUserStore(db *gorm.DB) *GormUserStore {
+	return &GormUserStore{db: db}
+}
+
+// GetUser 根据用户 ID 获取用户.
+func (s *GormUserStore) GetUser(ctx context.Context, userID string) (*model.UserM, error) {
+	return s.getBy(ctx, "userID", userID)
+}
+
+// GetUserByUsername 根据用户名获取用户.
+func (s *GormUserStore) GetUserByUsername(ctx context.Context, username string) (*model.UserM, error) {
+	return s.getBy(ctx, "username", username)
+}
+
+// GetUserByPhone 根据手机号获取用户.
+func (s *GormUserStore) GetUserByPhone(ctx context.Context, phone string) (*model.UserM, error) {
+	return s.getBy(ctx, "phone", phone)
+}
+
+// CreateUser 创建用户.
+func (s *GormUserStore) CreateUser(ctx context.Context, user *model.UserM) error {
+	return s.db.WithContext(ctx).Create(user).Error
+}
+
+// UpdateUser 根据 user.UserID 更新用户的全部字段，用户不存在时返回 ErrUserNotFound.
+func (s *GormUserStore) UpdateUser(ctx context.Context, user *model.UserM) error {
+	result := s.db.WithContext(ctx).Model(&model.UserM{}).Where("userID = ?", user.UserID).
+		Select("username", "password", "nickname", "email", "phone", "updatedAt").Updates(user)
+	return rowsAffected(result)
+}
+
+// UpdatePassword 更新用户的密码哈希，用户不存在时返回 ErrUserNotFound.
+func (s *GormUserStore) UpdatePassword(ctx context.Context, userID string, encoded string) error {
+	result := s.db.WithContext(ctx).Model(&model.UserM{}).Where("userID = ?", userID).Update("password", encoded)
+	return rowsAffected(result)
+}
+
+// DeleteUser 软删除用户，用户不存在时返回 ErrUserNotFound.
+func (s *GormUserStore) DeleteUser(ctx context.Context, userID string) error {
+	result := s.db.WithContext(ctx).Where("userID = ?", userID).Delete(&model.UserM{})
+	return rowsAffected(result)
+}
+
+// ListUsers 按创建顺序分页返回用户，以及用户总数. limit 小于等于 0 时返回全部用户.
+func (s *GormUserStore) ListUsers(ctx context.Context, offset, limit int) (int64, []*model.UserM, error) {
+	db := s.db.WithContext(ctx).Model(&model.UserM{})
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return 0, nil, err
+	}
+
+	if limit <= 0 {
+		limit = -1
+	}
+
+	var users []*model.UserM
+	if err := db.Order("id").Offset(offset).Limit(limit).Find(&users).Error; err != nil {
+		return 0, nil, err
+	}
+
+	return total, users, nil
+}
+
+// getBy 根据唯一字段 column 获取用户.
+func (s *GormUserStore) getBy(ctx context.Context, column, value string) (*model.UserM, error) {
+	var user model.UserM
+	if err := s.db.WithContext(ctx).Where(column+" = ?", value).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+	return &user, nil
+}
+
+// rowsAffected 将没有影响任何行的更新和删除转换为 ErrUserNotFound.
+func rowsAffected(result *gorm.DB) error {
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrUserNotFound
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+/**FileHeader
+ * @Author: Liangkang Zhang
+ * @Date: 2026/2/9 21:26:50
+ * @LastEditors: Liangkang Zhang
+ * @LastEditTime: 2026/2/9 21:26:50
+ * @Description:
+ * @Copyright: Copyright (©)}) 2026 Liangkang Zhang<lkzhang98@gmail.com>. All rights reserved. Use of this source code is governed by a MIT style license that can be found in the LICENSE file.. All rights reserved.
+ * @Email: lkzhang98@gmail.com
+ * @Repository: https://github.com/geminik12/autostack
+ */
+package token
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// 注册声明（registered claims）校验失败时返回的预定义错误.
+// 时间相关的错误同时包装了 jwt 包中对应的错误，便于使用 errors.Is 判断.
+var (
+	ErrTokenExpired          = fmt.Errorf("token is expired: %w", jwt.ErrTokenExpired)
+	ErrTokenNotValidYet      = fmt.Errorf("token is not valid yet: %w", jwt.ErrTokenNotValidYet)
+	ErrTokenUsedBeforeIssued = fmt.Errorf("token used before issued: %w", jwt.ErrTokenUsedBeforeIssued)
+	ErrInvalidIssuer         = fmt.Errorf("invalid issuer in token: %w", jwt.ErrTokenInvalidIssuer)
+	ErrInvalidAudience       = fmt.Errorf("invalid audience in token: %w", jwt.ErrTokenInvalidAudience)
+	ErrInvalidSubject        = fmt.Errorf("invalid subject in token: %w", jwt.ErrTokenInvalidClaims)
+)
+
+// validateClaims 校验 token 的注册声明：exp、nbf、iat（允许 leeway 的时钟偏差）以及 iss、aud.
+// checkExpiry 为 false 时跳过 exp 校验，用于刷新已过期的 token.
+func (m *Manager) validateClaims(claims jwt.MapClaims, checkExpiry bool) error {
+	now := time.Now()
+	leeway := m.config.leeway
+
+	if checkExpiry {
+		if exp, ok, err := optionalTimeClaim(claims, "exp"); err != nil {
+			return err
+		} else if ok && now.After(exp.Add(leeway)) {
+			return ErrTokenExpired
+		}
+	}
+
+	if nbf, ok, err := optionalTimeClaim(claims, "nbf"); err != nil {
+		return err
+	} else if ok && now.Add(leeway).Before(nbf) {
+		return ErrTokenNotValidYet
+	}
+
+	if iat, ok, err := optionalTimeClaim(claims, "iat"); err != nil {
+		return err
+	} else if ok && now.Add(leeway).Before(iat) {
+		return ErrTokenUsedBeforeIssued
+	}
+
+	if m.config.issuer != "" {
+		if iss, _ := claims["iss"].(string); iss != m.config.issuer {
+			return ErrInvalidIssuer
+		}
+	}
+
+	if len(m.config.audience) > 0 && !containsAny(audienceOf(claims), m.config.audience) {
+		return ErrInvalidAudience
+	}
+
+	return nil
+}
+
+// stampRegisteredClaims 在签发 token 时写入配置的 iss 和 aud，已存在的值不会被覆盖.
+func (m *Manager) stampRegisteredClaims(claims jwt.MapClaims) {
+	if _, exists := claims["iss"]; !exists && m.config.issuer != "" {
+		claims["iss"] = m.config.issuer
+	}
+
+	if _, exists := claims["aud"]; !exists && len(m.config.audience) > 0 {
+		if len(m.config.audience) == 1 {
+			claims["aud"] = m.config.audience[0]
+		} else {
+			claims["aud"] = append([]string{}, m.config.audience...)
+		}
+	}
+}
+
+// optionalTimeClaim 读取可选的时间类 claim，claim 存在但格式错误时返回 ErrInvalidTokenClaims.
+func optionalTimeClaim(claims jwt.MapClaims, name string) (time.Time, bool, error) {
+	if _, exists := claims[name]; !exists {
+		return time.Time{}, false, nil
+	}
+
+	t, ok := timeClaim(claims, name)
+	if !ok {
+		return time.Time{}, false, ErrInvalidTokenClaims
+	}
+
+	return t, true, nil
+}
+
+// audienceOf 读取 aud claim，aud 可以是字符串或字符串数组.
+func audienceOf(claims jwt.MapClaims) []string {
+	switch v := claims["aud"].(type) {
+	case string:
+		return []string{v}
+	case []string:
+		return v
+	case []any:
+		aud := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				aud = append(aud, s)
+			}
+		}
+		return aud
+	default:
+		return nil
+	}
+}
+
+// containsAny 判断 values 中是否至少有一个元素出现在 accepted 中.
+func containsAny(values, accepted []string) bool {
+	for _, v := range values {
+		for _, a := range accepted {
+			if v == a {
+				return true
+			}
+		}
+	}
+	return false
+}
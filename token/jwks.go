@@ -0,0 +1,378 @@
+/**FileHeader
+ * @Author: Liangkang Zhang
+ * @Date: 2026/2/5 21:18:40
+ * @LastEditors: Liangkang Zhang
+ * @LastEditTime: 2026/2/5 21:18:40
+ * @Description:
+ * @Copyright: Copyright (©)}) 2026 Liangkang Zhang<lkzhang98@gmail.com>. All rights reserved. Use of this source code is governed by a MIT style license that can be found in the LICENSE file.. All rights reserved.
+ * @Email: lkzhang98@gmail.com
+ * @Repository: https://github.com/geminik12/autostack
+ */
+package token
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// JWKSPath 是 JWKS 端点的标准路径.
+const JWKSPath = "/.well-known/jwks.json"
+
+// JWKS 相关的预定义错误
+var (
+	ErrUnsupportedJWK = errors.New("unsupported json web key")
+	ErrFetchJWKS      = errors.New("failed to fetch json web key set")
+)
+
+// JWK 表示一个 JSON Web Key（RFC 7517），只包含公钥信息.
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid,omitempty"`
+	Use string `json:"use,omitempty"`
+	Alg string `json:"alg,omitempty"`
+	// RSA 公钥参数
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+	// EC / OKP 公钥参数
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+// JWKSet 表示一个 JSON Web Key Set.
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS 返回 keyring 中所有非对称密钥的公钥（包括 active 和 retiring 密钥）.
+// HMAC 密钥为共享密钥，不会被导出.
+func (r *Keyring) JWKS() JWKSet {
+	set := JWKSet{Keys: []JWK{}}
+	for _, key := range r.Keys() {
+		jwk, err := key.JWK()
+		if err != nil {
+			continue
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+
+	return set
+}
+
+// JWK 将密钥的公钥部分转换为 JWK 格式.
+func (k *Key) JWK() (JWK, error) {
+	jwk := JWK{Kid: k.ID, Use: "sig", Alg: k.Method.Alg()}
+
+	switch pub := k.verifyKey.(type) {
+	case *rsa.PublicKey:
+		jwk.Kty = "RSA"
+		jwk.N = encodeSegment(pub.N.Bytes())
+		jwk.E = encodeSegment(big.NewInt(int64(pub.E)).Bytes())
+	case *ecdsa.PublicKey:
+		size := (pub.Curve.Params().BitSize + 7) / 8
+		jwk.Kty = "EC"
+		jwk.Crv = pub.Curve.Params().Name
+		jwk.X = encodeSegment(pub.X.FillBytes(make([]byte, size)))
+		jwk.Y = encodeSegment(pub.Y.FillBytes(make([]byte, size)))
+	case ed25519.PublicKey:
+		jwk.Kty = "OKP"
+		jwk.Crv = "Ed25519"
+		jwk.X = encodeSegment(pub)
+	default:
+		return JWK{}, ErrUnsupportedJWK
+	}
+
+	return jwk, nil
+}
+
+// Key 将 JWK 转换为仅用于验证的密钥.
+// 如果 JWK 没有声明 alg，则根据 kty 和 crv 推断签名算法.
+func (j JWK) Key() (*Key, error) {
+	if j.Kid == "" {
+		return nil, ErrEmptyKeyID
+	}
+	if j.Use != "" && j.Use != "sig" {
+		return nil, ErrUnsupportedJWK
+	}
+
+	var (
+		alg = j.Alg
+		pub any
+	)
+
+	switch j.Kty {
+	case "RSA":
+		n, err := decodeSegment(j.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeSegment(j.E)
+		if err != nil {
+			return nil, err
+		}
+		pub = &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+		if alg == "" {
+			alg = jwt.SigningMethodRS256.Alg()
+		}
+	case "EC":
+		curve, defaultAlg, err := curveOf(j.Crv)
+		if err != nil {
+			return nil, err
+		}
+		x, err := decodeSegment(j.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeSegment(j.Y)
+		if err != nil {
+			return nil, err
+		}
+		pub = &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		if alg == "" {
+			alg = defaultAlg
+		}
+	case "OKP":
+		if j.Crv != "Ed25519" {
+			return nil, ErrUnsupportedJWK
+		}
+		x, err := decodeSegment(j.X)
+		if err != nil {
+			return nil, err
+		}
+		if len(x) != ed25519.PublicKeySize {
+			return nil, ErrUnsupportedJWK
+		}
+		pub = ed25519.PublicKey(x)
+		if alg == "" {
+			alg = jwt.SigningMethodEdDSA.Alg()
+		}
+	default:
+		return nil, ErrUnsupportedJWK
+	}
+
+	method := jwt.GetSigningMethod(alg)
+	if method == nil {
+		return nil, ErrUnsupportedSigningMethod
+	}
+
+	return &Key{ID: j.Kid, Method: method, verifyKey: pub}, nil
+}
+
+// curveOf 根据 crv 返回椭圆曲线及其默认签名算法.
+func curveOf(crv string) (elliptic.Curve, string, error) {
+	switch crv {
+	case "P-256":
+		return elliptic.P256(), jwt.SigningMethodES256.Alg(), nil
+	case "P-384":
+		return elliptic.P384(), jwt.SigningMethodES384.Alg(), nil
+	case "P-521":
+		return elliptic.P521(), jwt.SigningMethodES512.Alg(), nil
+	default:
+		return nil, "", ErrUnsupportedJWK
+	}
+}
+
+func encodeSegment(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodeSegment(s string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(s)
+}
+
+// JWKSHandler 返回一个 Gin 处理函数，以 JSON Web Key Set 的形式发布 keyring 中的公钥.
+// 如果 keyring 为 nil，则使用 Init 时通过 WithKeyring 配置的 keyring.
+// 通常注册在 JWKSPath 路径下.
+func JWKSHandler(keyring *Keyring) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		kr := keyring
+		if kr == nil {
+			kr = Default().config.keyring
+		}
+
+		set := JWKSet{Keys: []JWK{}}
+		if kr != nil {
+			set = kr.JWKS()
+		}
+
+		c.Header("Cache-Control", "public, max-age=300")
+		c.JSON(http.StatusOK, set)
+	}
+}
+
+// RemoteKeySetOption 用于配置 RemoteKeySet.
+type RemoteKeySetOption func(*RemoteKeySet)
+
+// WithHTTPClient 设置获取 JWKS 使用的 HTTP 客户端.
+func WithHTTPClient(client *http.Client) RemoteKeySetOption {
+	return func(s *RemoteKeySet) {
+		if client != nil {
+			s.client = client
+		}
+	}
+}
+
+// WithCacheTTL 设置 JWKS 缓存的有效期，过期后下一次查找会重新获取.
+func WithCacheTTL(ttl time.Duration) RemoteKeySetOption {
+	return func(s *RemoteKeySet) {
+		if ttl > 0 {
+			s.cacheTTL = ttl
+		}
+	}
+}
+
+// WithMinRefreshInterval 设置两次刷新之间的最小间隔，
+// 防止携带未知 kid 的请求频繁触发远程获取.
+func WithMinRefreshInterval(interval time.Duration) RemoteKeySetOption {
+	return func(s *RemoteKeySet) {
+		if interval >= 0 {
+			s.minRefreshInterval = interval
+		}
+	}
+}
+
+// RemoteKeySet 从远程 JWKS 地址加载验证密钥并缓存，遇到未知 kid 时会刷新.
+type RemoteKeySet struct {
+	url                string
+	client             *http.Client
+	cacheTTL           time.Duration
+	minRefreshInterval time.Duration
+
+	mu          sync.RWMutex
+	keys        map[string]*Key
+	lastRefresh time.Time
+	// refreshMu 保证同一时刻只有一个刷新请求
+	refreshMu sync.Mutex
+}
+
+// NewRemoteKeySet 创建一个从 url 加载 JWKS 的 RemoteKeySet.
+func NewRemoteKeySet(url string, opts ...RemoteKeySetOption) *RemoteKeySet {
+	s := &RemoteKeySet{
+		url:                url,
+		client:             &http.Client{Timeout: 10 * time.Second},
+		cacheTTL:           time.Hour,
+		minRefreshInterval: time.Minute,
+		keys:               make(map[string]*Key),
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+// Lookup 根据 kid 查找验证密钥，缓存未命中或已过期时会刷新 JWKS.
+func (s *RemoteKeySet) Lookup(ctx context.Context, kid string) (*Key, error) {
+	s.mu.RLock()
+	key, ok := s.keys[kid]
+	last := s.lastRefresh
+	s.mu.RUnlock()
+
+	if ok && time.Since(last) <= s.cacheTTL {
+		return key, nil
+	}
+
+	if err := s.refresh(ctx, kid, last, !ok); err != nil {
+		// 刷新失败时继续使用缓存中的密钥
+		if ok {
+			return key, nil
+		}
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if key, ok = s.keys[kid]; !ok {
+		return nil, ErrUnknownKeyID
+	}
+
+	return key, nil
+}
+
+// Refresh 立即从远程地址重新加载 JWKS.
+func (s *RemoteKeySet) Refresh(ctx context.Context) error {
+	s.refreshMu.Lock()
+	defer s.refreshMu.Unlock()
+
+	return s.fetch(ctx)
+}
+
+// refresh 为查找 kid 重新加载 JWKS，since 是调用方读取缓存时的上次刷新时间.
+// 等待 refreshMu 期间其他 goroutine 可能已经完成刷新，此时直接使用其结果，避免并发请求重复获取或被限流.
+// throttle 为 true 时，如果距离上次刷新不足最小间隔则跳过.
+func (s *RemoteKeySet) refresh(ctx context.Context, kid string, since time.Time, throttle bool) error {
+	s.refreshMu.Lock()
+	defer s.refreshMu.Unlock()
+
+	s.mu.RLock()
+	_, ok := s.keys[kid]
+	last := s.lastRefresh
+	s.mu.RUnlock()
+
+	if last.After(since) || (ok && time.Since(last) <= s.cacheTTL) {
+		return nil
+	}
+
+	if throttle && !last.IsZero() && time.Since(last) < s.minRefreshInterval {
+		return ErrUnknownKeyID
+	}
+
+	return s.fetch(ctx)
+}
+
+// fetch 从远程地址获取 JWKS 并替换缓存，调用方需持有 refreshMu.
+func (s *RemoteKeySet) fetch(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrFetchJWKS, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%w: unexpected status code %d", ErrFetchJWKS, resp.StatusCode)
+	}
+
+	var set JWKSet
+	if err := json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		return fmt.Errorf("%w: %w", ErrFetchJWKS, err)
+	}
+
+	keys := make(map[string]*Key, len(set.Keys))
+	for _, jwk := range set.Keys {
+		// 跳过无法识别的密钥
+		key, err := jwk.Key()
+		if err != nil {
+			continue
+		}
+		keys[key.ID] = key
+	}
+
+	s.mu.Lock()
+	s.keys = keys
+	s.lastRefresh = time.Now()
+	s.mu.Unlock()
+
+	return nil
+}
@@ -0,0 +1,284 @@
+package token
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// newTestKey 创建一个 ES256 签名密钥.
+func newTestKey(t *testing.T, id string) *Key {
+	t.Helper()
+
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	der, err := x509.MarshalECPrivateKey(priv)
+	if err != nil {
+		t.Fatalf("marshal key: %v", err)
+	}
+
+	key, err := NewKey(id, "ES256", string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der})))
+	if err != nil {
+		t.Fatalf("NewKey: %v", err)
+	}
+
+	return key
+}
+
+// jwksServer 是通过 JWKSHandler 发布 keyring 公钥的 httptest 服务，记录 JWKS 被获取的次数.
+type jwksServer struct {
+	*httptest.Server
+
+	fetches atomic.Int32
+}
+
+func newJWKSServer(t *testing.T, keyring *Keyring) *jwksServer {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	s := &jwksServer{}
+	engine := gin.New()
+	engine.GET(JWKSPath, func(c *gin.Context) {
+		s.fetches.Add(1)
+		// 放慢响应，使并发的查找在刷新期间排队
+		time.Sleep(10 * time.Millisecond)
+		c.Next()
+	}, JWKSHandler(keyring))
+	s.Server = httptest.NewServer(engine)
+	t.Cleanup(s.Close)
+
+	return s
+}
+
+func TestRemoteKeySetLookup(t *testing.T) {
+	keyring, err := NewKeyring(newTestKey(t, "key-1"))
+	if err != nil {
+		t.Fatalf("NewKeyring: %v", err)
+	}
+	srv := newJWKSServer(t, keyring)
+	keySet := NewRemoteKeySet(srv.URL + JWKSPath)
+
+	key, err := keySet.Lookup(context.Background(), "key-1")
+	if err != nil {
+		t.Fatalf("Lookup: %v", err)
+	}
+	if key.ID != "key-1" || key.Method.Alg() != "ES256" || key.CanSign() {
+		t.Errorf("Lookup = kid %s alg %s canSign %v, want verification-only ES256 key-1", key.ID, key.Method.Alg(), key.CanSign())
+	}
+
+	// 缓存命中时不会再次获取 JWKS
+	if _, err := keySet.Lookup(context.Background(), "key-1"); err != nil {
+		t.Fatalf("Lookup: %v", err)
+	}
+	if n := srv.fetches.Load(); n != 1 {
+		t.Errorf("fetches = %d, want 1", n)
+	}
+}
+
+func TestRemoteKeySetRefreshOnUnknownKid(t *testing.T) {
+	keyring, err := NewKeyring(newTestKey(t, "key-1"))
+	if err != nil {
+		t.Fatalf("NewKeyring: %v", err)
+	}
+	srv := newJWKSServer(t, keyring)
+	keySet := NewRemoteKeySet(srv.URL+JWKSPath, WithMinRefreshInterval(0))
+
+	if _, err := keySet.Lookup(context.Background(), "key-1"); err != nil {
+		t.Fatalf("Lookup: %v", err)
+	}
+
+	// 轮换密钥后，携带新 kid 的 token 触发刷新
+	if err := keyring.Rotate(newTestKey(t, "key-2")); err != nil {
+		t.Fatalf("Rotate: %v", err)
+	}
+	if _, err := keySet.Lookup(context.Background(), "key-2"); err != nil {
+		t.Fatalf("Lookup after rotation: %v", err)
+	}
+	if n := srv.fetches.Load(); n != 2 {
+		t.Errorf("fetches = %d, want 2", n)
+	}
+
+	if _, err := keySet.Lookup(context.Background(), "key-3"); !errors.Is(err, ErrUnknownKeyID) {
+		t.Errorf("Lookup unknown kid error = %v, want %v", err, ErrUnknownKeyID)
+	}
+}
+
+func TestRemoteKeySetRefreshThrottle(t *testing.T) {
+	keyring, err := NewKeyring(newTestKey(t, "key-1"))
+	if err != nil {
+		t.Fatalf("NewKeyring: %v", err)
+	}
+	srv := newJWKSServer(t, keyring)
+	keySet := NewRemoteKeySet(srv.URL+JWKSPath, WithMinRefreshInterval(time.Hour))
+
+	if _, err := keySet.Lookup(context.Background(), "key-1"); err != nil {
+		t.Fatalf("Lookup: %v", err)
+	}
+
+	// 最小刷新间隔内，未知 kid 不会触发远程获取
+	for i := 0; i < 5; i++ {
+		if _, err := keySet.Lookup(context.Background(), "unknown"); !errors.Is(err, ErrUnknownKeyID) {
+			t.Fatalf("Lookup unknown kid error = %v, want %v", err, ErrUnknownKeyID)
+		}
+	}
+	if n := srv.fetches.Load(); n != 1 {
+		t.Errorf("fetches = %d, want 1", n)
+	}
+
+	// Refresh 不受最小刷新间隔限制
+	if err := keySet.Refresh(context.Background()); err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+	if n := srv.fetches.Load(); n != 2 {
+		t.Errorf("fetches after Refresh = %d, want 2", n)
+	}
+}
+
+func TestRemoteKeySetConcurrentLookup(t *testing.T) {
+	keyring, err := NewKeyring(newTestKey(t, "key-1"))
+	if err != nil {
+		t.Fatalf("NewKeyring: %v", err)
+	}
+	srv := newJWKSServer(t, keyring)
+	keySet := NewRemoteKeySet(srv.URL+JWKSPath, WithMinRefreshInterval(time.Hour))
+
+	// 并发查找同一个未缓存的 kid，只获取一次 JWKS，等待中的请求使用其结果而不是被限流
+	const n = 20
+	var (
+		wg   sync.WaitGroup
+		errs = make(chan error, n)
+	)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := keySet.Lookup(context.Background(), "key-1"); err != nil {
+				errs <- err
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Errorf("concurrent Lookup: %v", err)
+	}
+	if n := srv.fetches.Load(); n != 1 {
+		t.Errorf("fetches = %d, want 1", n)
+	}
+}
+
+func TestRemoteKeySetConcurrentStaleRefresh(t *testing.T) {
+	keyring, err := NewKeyring(newTestKey(t, "key-1"))
+	if err != nil {
+		t.Fatalf("NewKeyring: %v", err)
+	}
+	srv := newJWKSServer(t, keyring)
+	keySet := NewRemoteKeySet(srv.URL+JWKSPath, WithCacheTTL(time.Hour))
+
+	if _, err := keySet.Lookup(context.Background(), "key-1"); err != nil {
+		t.Fatalf("Lookup: %v", err)
+	}
+
+	// 缓存过期后并发查找，只有一个请求重新获取 JWKS
+	keySet.mu.Lock()
+	keySet.lastRefresh = time.Now().Add(-2 * time.Hour)
+	keySet.mu.Unlock()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := keySet.Lookup(context.Background(), "key-1"); err != nil {
+				t.Errorf("concurrent Lookup: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if n := srv.fetches.Load(); n != 2 {
+		t.Errorf("fetches = %d, want 2", n)
+	}
+}
+
+func TestKeyFuncHonorsContext(t *testing.T) {
+	signer := NewManager("", WithKeyring(mustKeyring(t, newTestKey(t, "key-1"))))
+	tokenString, _, err := signer.Sign("alice")
+	if err != nil {
+		t.Fatalf("Sign: %v", err)
+	}
+
+	// JWKS 端点在请求取消前不返回
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	verifier := NewManager("", WithJWKSURL(srv.URL))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	if _, err := verifier.parseClaims(ctx, tokenString, verifier.config.keyFunc(ctx)); err == nil {
+		t.Fatal("parseClaims succeeded, want error")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("parseClaims took %v, want it to return once ctx is done", elapsed)
+	}
+}
+
+func TestJWKSHandlerDefaultKeyring(t *testing.T) {
+	keyring := mustKeyring(t, newTestKey(t, "key-1"))
+
+	Reset()
+	Init("", WithKeyring(keyring))
+	t.Cleanup(Reset)
+
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.GET(JWKSPath, JWKSHandler(nil))
+
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, JWKSPath, nil))
+
+	var set JWKSet
+	if err := json.Unmarshal(w.Body.Bytes(), &set); err != nil {
+		t.Fatalf("decode jwks: %v", err)
+	}
+	if len(set.Keys) != 1 || set.Keys[0].Kid != "key-1" || set.Keys[0].Kty != "EC" {
+		t.Errorf("jwks = %+v, want the EC key key-1", set.Keys)
+	}
+}
+
+func mustKeyring(t *testing.T, keys ...*Key) *Keyring {
+	t.Helper()
+
+	keyring, err := NewKeyring(keys...)
+	if err != nil {
+		t.Fatalf("NewKeyring: %v", err)
+	}
+
+	return keyring
+}
@@ -0,0 +1,207 @@
+/**FileHeader
+ * @Author: Liangkang Zhang
+ * @Date: 2026/2/4 20:32:08
+ * @LastEditors: Liangkang Zhang
+ * @LastEditTime: 2026/2/4 20:32:08
+ * @Description:
+ * @Copyright: Copyright (©)}) 2026 Liangkang Zhang<lkzhang98@gmail.com>. All rights reserved. Use of this source code is governed by a MIT style license that can be found in the LICENSE file.. All rights reserved.
+ * @Email: lkzhang98@gmail.com
+ * @Repository: https://github.com/geminik12/autostack
+ */
+package token
+
+import (
+	"errors"
+	"sort"
+	"sync"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// keyring 相关的预定义错误
+var (
+	ErrEmptyKeyID        = errors.New("key id is empty")
+	ErrUnknownKeyID      = errors.New("unknown key id in token")
+	ErrDuplicateKeyID    = errors.New("key id already exists in keyring")
+	ErrNoActiveKey       = errors.New("no active signing key in keyring")
+	ErrKeyCannotSign     = errors.New("key is verification only and cannot be used for signing")
+	ErrRetireActiveKey   = errors.New("active signing key cannot be retired")
+	ErrKeyNotFoundInRing = errors.New("key not found in keyring")
+)
+
+// Key 表示 keyring 中的一个密钥.
+type Key struct {
+	// ID 是密钥标识，签发 token 时会写入 kid 头部.
+	ID string
+	// Method 是该密钥使用的签名算法.
+	Method jwt.SigningMethod
+
+	signKey   any
+	verifyKey any
+}
+
+// NewKey 创建一个可用于签发和验证的密钥.
+// HMAC 算法的 key 为共享密钥，非对称算法的 key 为 PEM 编码的私钥.
+func NewKey(id, alg, key string) (*Key, error) {
+	if id == "" {
+		return nil, ErrEmptyKeyID
+	}
+
+	method := jwt.GetSigningMethod(alg)
+	signKey, err := parseSigningKey(method, key)
+	if err != nil {
+		return nil, err
+	}
+
+	verifyKey, err := publicKeyOf(signKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Key{ID: id, Method: method, signKey: signKey, verifyKey: verifyKey}, nil
+}
+
+// NewVerificationKey 创建一个仅用于验证的密钥.
+// HMAC 算法的 key 为共享密钥，非对称算法的 key 为 PEM 编码的公钥.
+func NewVerificationKey(id, alg, key string) (*Key, error) {
+	if id == "" {
+		return nil, ErrEmptyKeyID
+	}
+
+	method := jwt.GetSigningMethod(alg)
+	verifyKey, err := parseVerifyKey(method, key)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Key{ID: id, Method: method, verifyKey: verifyKey}, nil
+}
+
+// CanSign 返回该密钥是否可以用于签发 token.
+func (k *Key) CanSign() bool {
+	return k.signKey != nil
+}
+
+// Keyring 保存一组用于签发和验证 token 的密钥，支持在运行时轮换密钥.
+// 同一时刻只有一个 active 密钥用于签发 token，其余密钥处于 retiring 状态，仅用于验证
+// 之前签发的 token，直到被 Retire 从 keyring 中移除.
+type Keyring struct {
+	mu     sync.RWMutex
+	keys   map[string]*Key
+	active string
+}
+
+// NewKeyring 创建一个 keyring，第一个可签名的密钥会成为 active 密钥.
+func NewKeyring(keys ...*Key) (*Keyring, error) {
+	r := &Keyring{keys: make(map[string]*Key)}
+	for _, key := range keys {
+		if err := r.Add(key); err != nil {
+			return nil, err
+		}
+		if r.active == "" && key.CanSign() {
+			r.active = key.ID
+		}
+	}
+
+	return r, nil
+}
+
+// Add 添加一个密钥，新添加的密钥仅用于验证，需要调用 Activate 才会用于签发.
+// 可以提前把新密钥分发给所有实例，再统一激活.
+func (r *Keyring) Add(key *Key) error {
+	if key == nil || key.ID == "" {
+		return ErrEmptyKeyID
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.keys[key.ID]; exists {
+		return ErrDuplicateKeyID
+	}
+	r.keys[key.ID] = key
+
+	return nil
+}
+
+// Activate 将指定密钥设置为 active 密钥，原 active 密钥转为 retiring 状态.
+func (r *Keyring) Activate(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key, ok := r.keys[id]
+	if !ok {
+		return ErrKeyNotFoundInRing
+	}
+	if !key.CanSign() {
+		return ErrKeyCannotSign
+	}
+	r.active = id
+
+	return nil
+}
+
+// Rotate 添加一个新密钥并立即将其设置为 active 密钥.
+func (r *Keyring) Rotate(key *Key) error {
+	if key != nil && !key.CanSign() {
+		return ErrKeyCannotSign
+	}
+
+	if err := r.Add(key); err != nil {
+		return err
+	}
+
+	return r.Activate(key.ID)
+}
+
+// Retire 从 keyring 中移除一个 retiring 密钥，使用该密钥签发的 token 将无法再通过验证.
+func (r *Keyring) Retire(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.keys[id]; !ok {
+		return ErrKeyNotFoundInRing
+	}
+	if id == r.active {
+		return ErrRetireActiveKey
+	}
+	delete(r.keys, id)
+
+	return nil
+}
+
+// Active 返回当前用于签发 token 的密钥.
+func (r *Keyring) Active() (*Key, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	key, ok := r.keys[r.active]
+	if !ok {
+		return nil, ErrNoActiveKey
+	}
+
+	return key, nil
+}
+
+// Lookup 根据 kid 查找验证密钥.
+func (r *Keyring) Lookup(id string) (*Key, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	key, ok := r.keys[id]
+	return key, ok
+}
+
+// Keys 返回 keyring 中的所有密钥，按 kid 排序.
+func (r *Keyring) Keys() []*Key {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	keys := make([]*Key, 0, len(r.keys))
+	for _, key := range r.keys {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].ID < keys[j].ID })
+
+	return keys
+}
@@ -0,0 +1,107 @@
+/**FileHeader
+ * @Author: Liangkang Zhang
+ * @Date: 2026/2/3 21:10:12
+ * @LastEditors: Liangkang Zhang
+ * @LastEditTime: 2026/2/3 21:10:12
+ * @Description:
+ * @Copyright: Copyright (©)}) 2026 Liangkang Zhang<lkzhang98@gmail.com>. All rights reserved. Use of this source code is governed by a MIT style license that can be found in the LICENSE file.. All rights reserved.
+ * @Email: lkzhang98@gmail.com
+ * @Repository: https://github.com/geminik12/autostack
+ */
+package token
+
+import (
+	"crypto"
+	"fmt"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// parseSigningKey 根据签名算法将 key 解析为签发 token 所需的密钥.
+// HMAC 算法直接使用 key 作为共享密钥，非对称算法要求 key 为 PEM 编码的私钥.
+func parseSigningKey(method jwt.SigningMethod, key string) (any, error) {
+	if method == nil {
+		return nil, ErrUnsupportedSigningMethod
+	}
+
+	if key == "" {
+		return nil, jwt.ErrInvalidKey
+	}
+
+	switch method.(type) {
+	case *jwt.SigningMethodHMAC:
+		return []byte(key), nil
+	case *jwt.SigningMethodRSA, *jwt.SigningMethodRSAPSS:
+		return jwt.ParseRSAPrivateKeyFromPEM([]byte(key))
+	case *jwt.SigningMethodECDSA:
+		return jwt.ParseECPrivateKeyFromPEM([]byte(key))
+	case *jwt.SigningMethodEd25519:
+		return jwt.ParseEdPrivateKeyFromPEM([]byte(key))
+	default:
+		return nil, ErrUnsupportedSigningMethod
+	}
+}
+
+// parseVerifyKey 根据签名算法将 key 解析为验证 token 所需的密钥.
+// 对于非对称算法，key 可以是 PEM 编码的公钥，也可以是 PEM 编码的私钥（此时使用其对应的公钥）.
+func parseVerifyKey(method jwt.SigningMethod, key string) (any, error) {
+	if method == nil {
+		return nil, ErrUnsupportedSigningMethod
+	}
+
+	if key == "" {
+		return nil, jwt.ErrInvalidKey
+	}
+
+	var (
+		pub any
+		err error
+	)
+
+	switch method.(type) {
+	case *jwt.SigningMethodHMAC:
+		return []byte(key), nil
+	case *jwt.SigningMethodRSA, *jwt.SigningMethodRSAPSS:
+		pub, err = jwt.ParseRSAPublicKeyFromPEM([]byte(key))
+	case *jwt.SigningMethodECDSA:
+		pub, err = jwt.ParseECPublicKeyFromPEM([]byte(key))
+	case *jwt.SigningMethodEd25519:
+		pub, err = jwt.ParseEdPublicKeyFromPEM([]byte(key))
+	default:
+		return nil, ErrUnsupportedSigningMethod
+	}
+	if err == nil {
+		return pub, nil
+	}
+
+	// 尝试将 key 作为私钥解析，并取其公钥
+	priv, perr := parseSigningKey(method, key)
+	if perr != nil {
+		return nil, fmt.Errorf("failed to parse verification key: %w", err)
+	}
+
+	return publicKeyOf(priv)
+}
+
+// publicKeyOf 返回签名密钥对应的验证密钥.
+func publicKeyOf(signKey any) (any, error) {
+	switch typed := signKey.(type) {
+	case []byte:
+		return typed, nil
+	case crypto.Signer:
+		return typed.Public(), nil
+	default:
+		return nil, jwt.ErrInvalidKeyType
+	}
+}
+
+// keyFuncFor 返回一个 jwt.Keyfunc，它会校验 token 的签名算法与预期算法一致，并返回验证密钥.
+func keyFuncFor(method jwt.SigningMethod, verifyKey any) jwt.Keyfunc {
+	return func(token *jwt.Token) (interface{}, error) {
+		// 确保 token 加密算法符合预期的加密算法
+		if method == nil || token.Method.Alg() != method.Alg() {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return verifyKey, nil
+	}
+}
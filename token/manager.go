@@ -0,0 +1,194 @@
+/**FileHeader
+ * @Author: Liangkang Zhang
+ * @Date: 2026/2/8 19:52:14
+ * @LastEditors: Liangkang Zhang
+ * @LastEditTime: 2026/2/8 19:52:14
+ * @Description:
+ * @Copyright: Copyright (©)}) 2026 Liangkang Zhang<lkzhang98@gmail.com>. All rights reserved. Use of this source code is governed by a MIT style license that can be found in the LICENSE file.. All rights reserved.
+ * @Email: lkzhang98@gmail.com
+ * @Repository: https://github.com/geminik12/autostack
+ */
+package token
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// Manager 持有一份独立的 token 配置，负责签发和解析 token.
+// 同一个进程可以创建多个 Manager，例如分别用于用户 token 和服务间调用的 token，
+// 它们可以使用不同的密钥、算法和有效期.
+type Manager struct {
+	config Config
+}
+
+var (
+	// defaultManager 是包级别函数使用的默认 Manager.
+	// Init 和 Reset 可能与请求处理并发执行，因此使用原子指针保存.
+	defaultManager atomic.Pointer[Manager]
+	once           sync.Once // 确保默认 Manager 只被初始化一次
+)
+
+func init() {
+	defaultManager.Store(&Manager{config: defaultConfig()})
+}
+
+// NewManager 使用密钥 key 和配置选项创建一个 Manager.
+func NewManager(key string, opts ...Option) *Manager {
+	cfg := defaultConfig()
+	if key != "" {
+		cfg.key = key // 设置密钥
+	}
+
+	// 应用所有配置选项
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	cfg.loadKeys()
+
+	return &Manager{config: cfg}
+}
+
+// Default 返回包级别函数使用的默认 Manager.
+func Default() *Manager {
+	return defaultManager.Load()
+}
+
+// Init 初始化默认 Manager 的配置，该配置会用于本包后面的 token 签发和解析.
+func Init(key string, opts ...Option) {
+	once.Do(func() {
+		defaultManager.Store(NewManager(key, opts...))
+	})
+}
+
+// Reset 重置默认 Manager 的配置（主要用于测试）
+func Reset() {
+	once = sync.Once{}
+	defaultManager.Store(NewManager("Rtg8BPKNEf2mB4mgvKONGPZZQSaJWNLijxR42qRgq0iBb5", WithIdentityKey("identityKey")))
+}
+
+// Config 获取 Manager 的当前配置（用于调试和测试）
+func (m *Manager) Config() Config {
+	return m.config
+}
+
+// IsIdentityRequired 检查是否需要身份验证
+func (m *Manager) IsIdentityRequired() bool {
+	return m.config.useSubject || m.config.identityKey != ""
+}
+
+// GetMaxRefresh 获取配置的最长刷新时间
+func (m *Manager) GetMaxRefresh() time.Duration {
+	return m.config.maxRefresh
+}
+
+// GetExpiration 获取配置的过期时间
+func (m *Manager) GetExpiration() time.Duration {
+	return m.config.expiration
+}
+
+// GetSkipPaths 获取跳过认证的路径列表
+func (m *Manager) GetSkipPaths() []string {
+	return append([]string{}, m.config.skipPaths...) // 返回副本
+}
+
+// IsPathSkipped 检查指定路径是否被跳过认证
+func (m *Manager) IsPathSkipped(path string) bool {
+	return m.shouldSkipPath(path)
+}
+
+// ParseIdentity 使用指定的密钥 key 解析 token，解析成功返回 token 上下文，否则报错.
+// key 的格式由配置的签名算法决定：HMAC 算法为共享密钥，非对称算法为 PEM 编码的公钥或私钥.
+func ParseIdentity(tokenString string, key string) (string, error) {
+	return Default().ParseIdentity(tokenString, key)
+}
+
+// ParseRequest 从请求头中获取令牌，并将其传递递给 Parse 函数以解析令牌.
+func ParseRequest(ctx context.Context) (string, error) {
+	return Default().ParseRequest(ctx)
+}
+
+// ParseRequestWithClaims 解析请求中的令牌，同时返回身份和所有 claims.
+func ParseRequestWithClaims(ctx context.Context) (string, jwt.MapClaims, error) {
+	return Default().ParseRequestWithClaims(ctx)
+}
+
+// ParseRequestIgnoreSkip 强制解析请求，忽略跳过路径设置
+func ParseRequestIgnoreSkip(ctx context.Context) (string, error) {
+	return Default().ParseRequestIgnoreSkip(ctx)
+}
+
+// Sign 使用 jwtSecret 签发 token，token 的 claims 中会存放传入的 subject.
+func Sign(identityValue string) (string, time.Time, error) {
+	return Default().Sign(identityValue)
+}
+
+// SignWithClaims 使用自定义 claims 签发 token
+func SignWithClaims(customClaims jwt.MapClaims) (string, time.Time, error) {
+	return Default().SignWithClaims(customClaims)
+}
+
+// Refresh 使用旧 token 换取一个新 token，详见 Manager.Refresh.
+func Refresh(oldToken string) (string, time.Time, error) {
+	return Default().Refresh(oldToken)
+}
+
+// Parse 验证 token 字符串的有效性（不解析身份信息）
+func Parse(tokenString string) error {
+	return Default().Parse(tokenString)
+}
+
+// GetClaims 获取 token 中的所有 claims
+func GetClaims(tokenString string) (jwt.MapClaims, error) {
+	return Default().GetClaims(tokenString)
+}
+
+// ParseWithKey 使用自定义密钥解析 token
+func ParseWithKey(tokenString, key string) (jwt.MapClaims, error) {
+	return Default().ParseWithKey(tokenString, key)
+}
+
+// Revoke 吊销一个 token，详见 Manager.Revoke.
+func Revoke(ctx context.Context, tokenString string) error {
+	return Default().Revoke(ctx, tokenString)
+}
+
+// RevokeAllForUser 吊销 identity 在 before 所在的秒之前签发的所有 token，详见 Manager.RevokeAllForUser.
+func RevokeAllForUser(ctx context.Context, identity string, before time.Time) error {
+	return Default().RevokeAllForUser(ctx, identity, before)
+}
+
+// GetConfig 获取当前配置（用于调试和测试）
+func GetConfig() Config {
+	return Default().Config()
+}
+
+// IsIdentityRequired 检查是否需要身份验证
+func IsIdentityRequired() bool {
+	return Default().IsIdentityRequired()
+}
+
+// GetMaxRefresh 获取当前配置的最长刷新时间
+func GetMaxRefresh() time.Duration {
+	return Default().GetMaxRefresh()
+}
+
+// GetExpiration 获取当前配置的过期时间
+func GetExpiration() time.Duration {
+	return Default().GetExpiration()
+}
+
+// GetSkipPaths 获取跳过认证的路径列表
+func GetSkipPaths() []string {
+	return Default().GetSkipPaths()
+}
+
+// IsPathSkipped 检查指定路径是否被跳过认证
+func IsPathSkipped(path string) bool {
+	return Default().IsPathSkipped(path)
+}
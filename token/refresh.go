@@ -0,0 +1,373 @@
+/**FileHeader
+ * @Author: Liangkang Zhang
+ * @Date: 2026/2/6 22:05:31
+ * @LastEditors: Liangkang Zhang
+ * @LastEditTime: 2026/2/6 22:05:31
+ * @Description:
+ * @Copyright: Copyright (©)}) 2026 Liangkang Zhang<lkzhang98@gmail.com>. All rights reserved. Use of this source code is governed by a MIT style license that can be found in the LICENSE file.. All rights reserved.
+ * @Email: lkzhang98@gmail.com
+ * @Repository: https://github.com/geminik12/autostack
+ */
+package token
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/geminik12/autostack/core"
+	"github.com/geminik12/autostack/errorsx"
+	"github.com/gin-gonic/gin"
+)
+
+// 刷新令牌相关的预定义错误
+var (
+	ErrRefreshTokenNotFound = errors.New("refresh token not found")
+	ErrRefreshTokenExpired  = errors.New("refresh token is expired")
+	ErrRefreshTokenRevoked  = errors.New("refresh token is revoked")
+	ErrRefreshTokenReused   = errors.New("refresh token reuse detected, token family revoked")
+)
+
+// RefreshToken 是一个不透明刷新令牌在存储中的记录.
+// 令牌明文只在签发时返回给客户端，存储中只保存其哈希值.
+type RefreshToken struct {
+	// ID 是令牌明文的 SHA-256 哈希值.
+	ID string
+	// FamilyID 标识一条轮换链，同一次登录产生的所有刷新令牌共享同一个 FamilyID.
+	FamilyID string
+	// Identity 是令牌所属用户的身份标识，用于签发新的访问令牌.
+	Identity string
+	// IssuedAt 是令牌的签发时间.
+	IssuedAt time.Time
+	// ExpiresAt 是令牌的过期时间.
+	ExpiresAt time.Time
+	// Used 表示令牌是否已经被用于轮换.
+	Used bool
+	// Revoked 表示令牌所在的轮换链是否已被吊销.
+	Revoked bool
+}
+
+// RefreshTokenStore 定义了刷新令牌的存储接口.
+// 轮换（MarkUsed 后 Save）与 RevokeFamily 可能并发执行，实现需要保证吊销之后轮换链上不会再出现可用的令牌：
+// MarkUsed 和 Save 必须与 RevokeFamily 原子地互斥，对已吊销的令牌或轮换链返回 ErrRefreshTokenRevoked.
+type RefreshTokenStore interface {
+	// Save 保存一个新的刷新令牌记录，令牌所在的轮换链已被吊销时返回 ErrRefreshTokenRevoked.
+	Save(ctx context.Context, token *RefreshToken) error
+	// Get 根据 ID 获取刷新令牌记录，不存在时返回 ErrRefreshTokenNotFound.
+	Get(ctx context.Context, id string) (*RefreshToken, error)
+	// MarkUsed 原子地将令牌标记为已使用，如果令牌此前已被使用则返回 false，令牌已被吊销时返回 ErrRefreshTokenRevoked.
+	MarkUsed(ctx context.Context, id string) (bool, error)
+	// RevokeFamily 吊销一条轮换链中的所有令牌，并记录该轮换链已被吊销，之后不能再保存属于该轮换链的令牌.
+	RevokeFamily(ctx context.Context, familyID string) error
+}
+
+// TokenPair 包含一次签发或轮换产生的访问令牌和刷新令牌.
+type TokenPair struct {
+	AccessToken     string    `json:"accessToken"`
+	AccessExpireAt  time.Time `json:"accessExpireAt"`
+	RefreshToken    string    `json:"refreshToken"`
+	RefreshExpireAt time.Time `json:"refreshExpireAt"`
+}
+
+// RefreshTokenOption 用于配置 RefreshTokenIssuer.
+type RefreshTokenOption func(*RefreshTokenIssuer)
+
+// WithIssuerManager 设置签发访问令牌使用的 Manager，默认使用包级别的默认 Manager.
+func WithIssuerManager(manager *Manager) RefreshTokenOption {
+	return func(i *RefreshTokenIssuer) {
+		i.manager = manager
+	}
+}
+
+// WithRefreshTokenTTL 设置刷新令牌的有效期.
+func WithRefreshTokenTTL(ttl time.Duration) RefreshTokenOption {
+	return func(i *RefreshTokenIssuer) {
+		if ttl > 0 {
+			i.ttl = ttl
+		}
+	}
+}
+
+// RefreshTokenIssuer 负责签发和轮换不透明的长期刷新令牌.
+// 每个刷新令牌只能使用一次，使用后会签发新的刷新令牌；如果检测到已使用的令牌被再次提交，
+// 说明令牌可能已泄露，整条轮换链都会被吊销.
+type RefreshTokenIssuer struct {
+	store   RefreshTokenStore
+	ttl     time.Duration
+	manager *Manager
+}
+
+// NewRefreshTokenIssuer 创建一个使用 store 保存刷新令牌的 RefreshTokenIssuer.
+func NewRefreshTokenIssuer(store RefreshTokenStore, opts ...RefreshTokenOption) *RefreshTokenIssuer {
+	i := &RefreshTokenIssuer{
+		store: store,
+		ttl:   7 * 24 * time.Hour,
+	}
+
+	for _, opt := range opts {
+		opt(i)
+	}
+
+	return i
+}
+
+// Issue 为 identity 签发访问令牌和一条新轮换链上的刷新令牌，通常在登录成功后调用.
+func (i *RefreshTokenIssuer) Issue(ctx context.Context, identity string) (*TokenPair, error) {
+	familyID, err := randomString(16)
+	if err != nil {
+		return nil, err
+	}
+
+	return i.issue(ctx, identity, familyID)
+}
+
+// Rotate 使用刷新令牌换取新的访问令牌和刷新令牌，旧的刷新令牌随即失效.
+func (i *RefreshTokenIssuer) Rotate(ctx context.Context, refreshToken string) (*TokenPair, error) {
+	if refreshToken == "" {
+		return nil, ErrEmptyToken
+	}
+
+	id := hashRefreshToken(refreshToken)
+	record, err := i.store.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if record.Revoked {
+		return nil, ErrRefreshTokenRevoked
+	}
+
+	if time.Now().After(record.ExpiresAt) {
+		return nil, ErrRefreshTokenExpired
+	}
+
+	// 在 Get 之后并发吊销轮换链时，MarkUsed 或 issue 中的 Save 会返回 ErrRefreshTokenRevoked
+	ok, err := i.store.MarkUsed(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// 令牌已经被使用过，吊销整条轮换链
+	if !ok {
+		if err := i.store.RevokeFamily(ctx, record.FamilyID); err != nil {
+			return nil, err
+		}
+		return nil, ErrRefreshTokenReused
+	}
+
+	return i.issue(ctx, record.Identity, record.FamilyID)
+}
+
+// Revoke 吊销刷新令牌所在的整条轮换链，通常在用户登出时调用.
+func (i *RefreshTokenIssuer) Revoke(ctx context.Context, refreshToken string) error {
+	record, err := i.store.Get(ctx, hashRefreshToken(refreshToken))
+	if err != nil {
+		return err
+	}
+
+	return i.store.RevokeFamily(ctx, record.FamilyID)
+}
+
+// issue 在指定的轮换链上签发访问令牌和刷新令牌.
+func (i *RefreshTokenIssuer) issue(ctx context.Context, identity, familyID string) (*TokenPair, error) {
+	manager := i.manager
+	if manager == nil {
+		manager = Default()
+	}
+
+	accessToken, accessExpireAt, err := manager.Sign(identity)
+	if err != nil {
+		return nil, err
+	}
+
+	refreshToken, err := randomString(32)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	record := &RefreshToken{
+		ID:        hashRefreshToken(refreshToken),
+		FamilyID:  familyID,
+		Identity:  identity,
+		IssuedAt:  now,
+		ExpiresAt: now.Add(i.ttl),
+	}
+	if err := i.store.Save(ctx, record); err != nil {
+		return nil, err
+	}
+
+	return &TokenPair{
+		AccessToken:     accessToken,
+		AccessExpireAt:  accessExpireAt,
+		RefreshToken:    refreshToken,
+		RefreshExpireAt: record.ExpiresAt,
+	}, nil
+}
+
+// hashRefreshToken 计算刷新令牌明文的哈希值.
+func hashRefreshToken(refreshToken string) string {
+	sum := sha256.Sum256([]byte(refreshToken))
+	return hex.EncodeToString(sum[:])
+}
+
+// randomString 生成 n 字节的随机数并编码为 URL 安全的字符串.
+func randomString(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// MemoryRefreshTokenStore 是基于内存的 RefreshTokenStore 实现，适用于单实例部署和测试.
+type MemoryRefreshTokenStore struct {
+	mu       sync.Mutex
+	tokens   map[string]*RefreshToken
+	families map[string][]string
+	// revoked 记录已被吊销的轮换链，轮换链的令牌全部过期清理后一并删除
+	revoked map[string]struct{}
+}
+
+var _ RefreshTokenStore = (*MemoryRefreshTokenStore)(nil)
+
+// NewMemoryRefreshTokenStore 创建一个基于内存的刷新令牌存储.
+func NewMemoryRefreshTokenStore() *MemoryRefreshTokenStore {
+	return &MemoryRefreshTokenStore{
+		tokens:   make(map[string]*RefreshToken),
+		families: make(map[string][]string),
+		revoked:  make(map[string]struct{}),
+	}
+}
+
+// Save 保存一个新的刷新令牌记录.
+func (s *MemoryRefreshTokenStore) Save(ctx context.Context, token *RefreshToken) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.purgeExpired()
+
+	if _, ok := s.revoked[token.FamilyID]; ok {
+		return ErrRefreshTokenRevoked
+	}
+
+	record := *token
+	s.tokens[token.ID] = &record
+	s.families[token.FamilyID] = append(s.families[token.FamilyID], token.ID)
+
+	return nil
+}
+
+// Get 根据 ID 获取刷新令牌记录.
+func (s *MemoryRefreshTokenStore) Get(ctx context.Context, id string) (*RefreshToken, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	record, ok := s.tokens[id]
+	if !ok {
+		return nil, ErrRefreshTokenNotFound
+	}
+
+	copied := *record
+	return &copied, nil
+}
+
+// MarkUsed 将令牌标记为已使用.
+func (s *MemoryRefreshTokenStore) MarkUsed(ctx context.Context, id string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	record, ok := s.tokens[id]
+	if !ok {
+		return false, ErrRefreshTokenNotFound
+	}
+	if record.Revoked {
+		return false, ErrRefreshTokenRevoked
+	}
+	if record.Used {
+		return false, nil
+	}
+	record.Used = true
+
+	return true, nil
+}
+
+// RevokeFamily 吊销一条轮换链中的所有令牌.
+func (s *MemoryRefreshTokenStore) RevokeFamily(ctx context.Context, familyID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ids, ok := s.families[familyID]
+	if !ok {
+		return nil
+	}
+	for _, id := range ids {
+		if record, ok := s.tokens[id]; ok {
+			record.Revoked = true
+		}
+	}
+	s.revoked[familyID] = struct{}{}
+
+	return nil
+}
+
+// purgeExpired 清理已过期的令牌记录，调用方需持有锁.
+func (s *MemoryRefreshTokenStore) purgeExpired() {
+	now := time.Now()
+	for familyID, ids := range s.families {
+		alive := ids[:0]
+		for _, id := range ids {
+			if record, ok := s.tokens[id]; ok && now.After(record.ExpiresAt) {
+				delete(s.tokens, id)
+				continue
+			}
+			alive = append(alive, id)
+		}
+
+		if len(alive) == 0 {
+			delete(s.families, familyID)
+			delete(s.revoked, familyID)
+			continue
+		}
+		s.families[familyID] = alive
+	}
+}
+
+// RefreshTokenRequest 是刷新令牌接口的请求参数.
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refreshToken" binding:"required"`
+}
+
+// RefreshTokenHandler 返回一个 Gin 处理函数，使用请求体中的刷新令牌换取新的令牌对.
+// 刷新令牌无效、过期、被吊销或被重复使用时返回 401，其他错误返回 500.
+func RefreshTokenHandler(issuer *RefreshTokenIssuer) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		core.HandleJSONRequest(c, func(ctx context.Context, rq *RefreshTokenRequest) (*TokenPair, error) {
+			pair, err := issuer.Rotate(ctx, rq.RefreshToken)
+			if err != nil {
+				return nil, refreshError(err)
+			}
+			return pair, nil
+		})
+	}
+}
+
+// refreshError 将 Rotate 返回的错误转换为 API 错误.
+// 只有刷新令牌本身无效时返回 401，存储不可用等服务端错误返回 500，避免客户端在故障期间丢弃有效的会话.
+func refreshError(err error) error {
+	switch {
+	case errors.Is(err, ErrEmptyToken),
+		errors.Is(err, ErrRefreshTokenNotFound),
+		errors.Is(err, ErrRefreshTokenExpired),
+		errors.Is(err, ErrRefreshTokenRevoked),
+		errors.Is(err, ErrRefreshTokenReused):
+		return errorsx.ErrTokenInvalid.WithMessage("%s", err.Error())
+	default:
+		return errorsx.ErrInternal.WithMessage("%s", err.Error())
+	}
+}
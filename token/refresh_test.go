@@ -0,0 +1,71 @@
+package token
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// revokingStore 在 Rotate 的指定步骤之后吊销轮换链，模拟与 RevokeFamily 并发的轮换.
+type revokingStore struct {
+	*MemoryRefreshTokenStore
+	afterGet      bool
+	afterMarkUsed bool
+	familyID      string
+}
+
+func (s *revokingStore) Get(ctx context.Context, id string) (*RefreshToken, error) {
+	record, err := s.MemoryRefreshTokenStore.Get(ctx, id)
+	if err == nil && s.afterGet {
+		s.afterGet = false
+		_ = s.RevokeFamily(ctx, record.FamilyID)
+	}
+	return record, err
+}
+
+func (s *revokingStore) MarkUsed(ctx context.Context, id string) (bool, error) {
+	ok, err := s.MemoryRefreshTokenStore.MarkUsed(ctx, id)
+	if err == nil && s.afterMarkUsed {
+		s.afterMarkUsed = false
+		_ = s.RevokeFamily(ctx, s.familyID)
+	}
+	return ok, err
+}
+
+func TestRotateRacingRevokeFamily(t *testing.T) {
+	manager := NewManager("", WithKeyring(mustKeyring(t, newTestKey(t, "key-1"))))
+
+	tests := []struct {
+		name          string
+		afterGet      bool
+		afterMarkUsed bool
+	}{
+		{name: "revoked after get", afterGet: true},
+		{name: "revoked after mark used", afterMarkUsed: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			store := &revokingStore{MemoryRefreshTokenStore: NewMemoryRefreshTokenStore()}
+			issuer := NewRefreshTokenIssuer(store, WithIssuerManager(manager))
+
+			pair, err := issuer.Issue(ctx, "user-1")
+			if err != nil {
+				t.Fatalf("Issue: %v", err)
+			}
+			record, err := store.MemoryRefreshTokenStore.Get(ctx, hashRefreshToken(pair.RefreshToken))
+			if err != nil {
+				t.Fatalf("Get: %v", err)
+			}
+			store.familyID = record.FamilyID
+			store.afterGet, store.afterMarkUsed = tt.afterGet, tt.afterMarkUsed
+
+			if _, err := issuer.Rotate(ctx, pair.RefreshToken); !errors.Is(err, ErrRefreshTokenRevoked) {
+				t.Fatalf("Rotate: want ErrRefreshTokenRevoked, got %v", err)
+			}
+			if ids := store.families[record.FamilyID]; len(ids) != 1 {
+				t.Fatalf("family has %d tokens after revocation, want 1", len(ids))
+			}
+		})
+	}
+}
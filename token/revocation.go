@@ -0,0 +1,293 @@
+/**FileHeader
+ * @Author: Liangkang Zhang
+ * @Date: 2026/2/7 20:41:26
+ * @LastEditors: Liangkang Zhang
+ * @LastEditTime: 2026/2/7 20:41:26
+ * @Description:
+ * @Copyright: Copyright (©)}) 2026 Liangkang Zhang<lkzhang98@gmail.com>. All rights reserved. Use of this source code is governed by a MIT style license that can be found in the LICENSE file.. All rights reserved.
+ * @Email: lkzhang98@gmail.com
+ * @Repository: https://github.com/geminik12/autostack
+ */
+package token
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	redis "github.com/redis/go-redis/v9"
+)
+
+// 吊销相关的预定义错误
+var (
+	ErrTokenRevoked         = errors.New("token has been revoked")
+	ErrMissingTokenID       = errors.New("missing jti claim in token")
+	ErrRevocationNotEnabled = errors.New("token revocation store is not configured")
+)
+
+// RevocationStore 定义了 token 吊销列表的存储接口.
+type RevocationStore interface {
+	// Revoke 吊销 jti 对应的 token，记录需保留到 expiresAt（token 过期且不可再刷新的时间）.
+	Revoke(ctx context.Context, jti string, expiresAt time.Time) error
+	// RevokeIssuedBefore 吊销 identity 在 before 所在的秒之前签发的所有 token，
+	// 记录需保留到 expiresAt（即这些 token 中最晚不可再解析或刷新的时间）.
+	// iat 只精确到秒，与 before 同一秒签发的 token 不会被吊销，避免吊销后立即签发的新 token 被误判为已吊销.
+	RevokeIssuedBefore(ctx context.Context, identity string, before time.Time, expiresAt time.Time) error
+	// IsRevoked 检查 token 是否已被吊销，identity 为空时只检查 jti.
+	IsRevoked(ctx context.Context, jti string, identity string, issuedAt time.Time) (bool, error)
+}
+
+// Revoke 吊销一个 token，直到其无法再通过解析或刷新前都保留吊销记录.
+// token 的签名必须有效；已过期但仍在 maxRefresh 窗口内的 token 同样可以吊销，
+// 否则它仍能通过 Refresh 换取新 token.
+func (m *Manager) Revoke(ctx context.Context, tokenString string) error {
+	if m.config.revocationStore == nil {
+		return ErrRevocationNotEnabled
+	}
+
+	// 与 Refresh 一致，验证签名但跳过 exp 校验
+	parser := jwt.NewParser(jwt.WithoutClaimsValidation())
+	token, err := parser.Parse(tokenString, m.config.keyFunc(ctx))
+	if err != nil {
+		return err
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return ErrInvalidTokenClaims
+	}
+
+	if err := m.validateClaims(claims, false); err != nil {
+		return err
+	}
+
+	jti, _ := claims["jti"].(string)
+	if jti == "" {
+		return ErrMissingTokenID
+	}
+
+	// 既不能再通过解析也不能再刷新的 token 无需吊销
+	expiresAt := m.revocationDeadline(claims)
+	if !time.Now().Before(expiresAt) {
+		return ErrTokenExpired
+	}
+
+	return m.config.revocationStore.Revoke(ctx, jti, expiresAt)
+}
+
+// RevokeAllForUser 吊销 identity 在 before 所在的秒之前签发的所有 token，常用于"退出所有设备"或账号被盗场景.
+// 与 before 同一秒签发的 token 不受影响，因此吊销后可以立即为用户签发新 token.
+func (m *Manager) RevokeAllForUser(ctx context.Context, identity string, before time.Time) error {
+	if m.config.revocationStore == nil {
+		return ErrRevocationNotEnabled
+	}
+
+	// before 之前签发的 token 最晚在 before+expiration 过期，最晚在 before+maxRefresh 后不可再刷新
+	retention := max(m.config.expiration, m.config.maxRefresh) + m.config.leeway
+
+	return m.config.revocationStore.RevokeIssuedBefore(ctx, identity, before, before.Add(retention))
+}
+
+// revocationDeadline 返回 token 吊销记录需要保留到的时间，即 max(exp, orig_iat+maxRefresh).
+func (m *Manager) revocationDeadline(claims jwt.MapClaims) time.Time {
+	deadline, ok := timeClaim(claims, "exp")
+	if ok {
+		deadline = deadline.Add(m.config.leeway)
+	} else {
+		// 没有 exp 的 token 按配置的最长有效期保留吊销记录
+		deadline = time.Now().Add(m.config.expiration)
+	}
+
+	if origIat, err := originalIssuedAt(claims); err == nil {
+		if refreshUntil := origIat.Add(m.config.maxRefresh); refreshUntil.After(deadline) {
+			deadline = refreshUntil
+		}
+	}
+
+	return deadline
+}
+
+// checkRevocation 检查 claims 对应的 token 是否已被吊销.
+func (m *Manager) checkRevocation(ctx context.Context, claims jwt.MapClaims) error {
+	if m.config.revocationStore == nil {
+		return nil
+	}
+
+	jti, _ := claims["jti"].(string)
+	issuedAt, _ := timeClaim(claims, "iat")
+
+	identity, _ := m.extractIdentity(claims)
+
+	revoked, err := m.config.revocationStore.IsRevoked(ctx, jti, identity, issuedAt)
+	if err != nil {
+		return err
+	}
+	if revoked {
+		return ErrTokenRevoked
+	}
+
+	return nil
+}
+
+// RedisRevocationStore 是基于 Redis 的 RevocationStore 实现，记录的 TTL 等于 token 剩余的可解析或可刷新时间.
+type RedisRevocationStore struct {
+	client redis.UniversalClient
+	prefix string
+}
+
+var _ RevocationStore = (*RedisRevocationStore)(nil)
+
+// NewRedisRevocationStore 使用 db.NewRedis 创建的客户端构造 RedisRevocationStore.
+// prefix 为空时使用默认前缀 "token:revoked:".
+func NewRedisRevocationStore(client redis.UniversalClient, prefix string) *RedisRevocationStore {
+	if prefix == "" {
+		prefix = "token:revoked:"
+	}
+
+	return &RedisRevocationStore{client: client, prefix: prefix}
+}
+
+// Revoke 吊销 jti 对应的 token.
+func (s *RedisRevocationStore) Revoke(ctx context.Context, jti string, expiresAt time.Time) error {
+	ttl := time.Until(expiresAt)
+	if ttl <= 0 {
+		return nil
+	}
+
+	return s.client.Set(ctx, s.jtiKey(jti), 1, ttl).Err()
+}
+
+// RevokeIssuedBefore 吊销 identity 在 before 所在的秒之前签发的所有 token.
+func (s *RedisRevocationStore) RevokeIssuedBefore(ctx context.Context, identity string, before time.Time, expiresAt time.Time) error {
+	ttl := time.Until(expiresAt)
+	if ttl <= 0 {
+		return nil
+	}
+
+	return s.client.Set(ctx, s.identityKey(identity), before.Unix(), ttl).Err()
+}
+
+// IsRevoked 检查 token 是否已被吊销.
+func (s *RedisRevocationStore) IsRevoked(ctx context.Context, jti string, identity string, issuedAt time.Time) (bool, error) {
+	keys := make([]string, 0, 2)
+	if jti != "" {
+		keys = append(keys, s.jtiKey(jti))
+	}
+	if identity != "" {
+		keys = append(keys, s.identityKey(identity))
+	}
+	if len(keys) == 0 {
+		return false, nil
+	}
+
+	values, err := s.client.MGet(ctx, keys...).Result()
+	if err != nil {
+		return false, err
+	}
+
+	if jti != "" && values[0] != nil {
+		return true, nil
+	}
+
+	if identity != "" {
+		raw, ok := values[len(values)-1].(string)
+		if !ok {
+			return false, nil
+		}
+		before, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return false, err
+		}
+		return issuedAt.Unix() < before, nil
+	}
+
+	return false, nil
+}
+
+func (s *RedisRevocationStore) jtiKey(jti string) string {
+	return s.prefix + "jti:" + jti
+}
+
+func (s *RedisRevocationStore) identityKey(identity string) string {
+	return s.prefix + "identity:" + identity
+}
+
+// MemoryRevocationStore 是基于内存的 RevocationStore 实现，适用于单实例部署和测试.
+type MemoryRevocationStore struct {
+	mu         sync.Mutex
+	tokens     map[string]time.Time
+	identities map[string]revokedBefore
+}
+
+// revokedBefore 记录 identity 的批量吊销时间点及记录的过期时间.
+type revokedBefore struct {
+	before    time.Time
+	expiresAt time.Time
+}
+
+var _ RevocationStore = (*MemoryRevocationStore)(nil)
+
+// NewMemoryRevocationStore 创建一个基于内存的吊销存储.
+func NewMemoryRevocationStore() *MemoryRevocationStore {
+	return &MemoryRevocationStore{
+		tokens:     make(map[string]time.Time),
+		identities: make(map[string]revokedBefore),
+	}
+}
+
+// Revoke 吊销 jti 对应的 token.
+func (s *MemoryRevocationStore) Revoke(ctx context.Context, jti string, expiresAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.purgeExpired()
+	s.tokens[jti] = expiresAt
+
+	return nil
+}
+
+// RevokeIssuedBefore 吊销 identity 在 before 所在的秒之前签发的所有 token.
+func (s *MemoryRevocationStore) RevokeIssuedBefore(ctx context.Context, identity string, before time.Time, expiresAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.purgeExpired()
+	s.identities[identity] = revokedBefore{before: before, expiresAt: expiresAt}
+
+	return nil
+}
+
+// IsRevoked 检查 token 是否已被吊销.
+func (s *MemoryRevocationStore) IsRevoked(ctx context.Context, jti string, identity string, issuedAt time.Time) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if expiresAt, ok := s.tokens[jti]; ok && jti != "" && now.Before(expiresAt) {
+		return true, nil
+	}
+
+	if record, ok := s.identities[identity]; ok && identity != "" && now.Before(record.expiresAt) {
+		return issuedAt.Unix() < record.before.Unix(), nil
+	}
+
+	return false, nil
+}
+
+// purgeExpired 清理已过期的吊销记录，调用方需持有锁.
+func (s *MemoryRevocationStore) purgeExpired() {
+	now := time.Now()
+	for jti, expiresAt := range s.tokens {
+		if now.After(expiresAt) {
+			delete(s.tokens, jti)
+		}
+	}
+	for identity, record := range s.identities {
+		if now.After(record.expiresAt) {
+			delete(s.identities, identity)
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package token
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMemoryRevocationStoreIssuedBefore(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryRevocationStore()
+
+	before := time.Unix(1_000_000, 700_000_000)
+	if err := store.RevokeIssuedBefore(ctx, "user-1", before, time.Now().Add(time.Hour)); err != nil {
+		t.Fatalf("RevokeIssuedBefore: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		issuedAt time.Time
+		revoked  bool
+	}{
+		{name: "earlier second", issuedAt: time.Unix(999_999, 0), revoked: true},
+		{name: "same second", issuedAt: time.Unix(1_000_000, 0), revoked: false},
+		{name: "later second", issuedAt: time.Unix(1_000_001, 0), revoked: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			revoked, err := store.IsRevoked(ctx, "", "user-1", tt.issuedAt)
+			if err != nil {
+				t.Fatalf("IsRevoked: %v", err)
+			}
+			if revoked != tt.revoked {
+				t.Fatalf("IsRevoked = %v, want %v", revoked, tt.revoked)
+			}
+		})
+	}
+}
@@ -12,15 +12,17 @@ package token
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"strings"
-	"sync"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
 	"github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/auth"
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
@@ -31,24 +33,52 @@ type Config struct {
 	key string
 	// identityKey 是 token 中用户身份的键.
 	identityKey string
+	// useSubject 为 true 时使用标准的 sub claim 保存用户身份，而不是 identityKey
+	useSubject bool
+	// issuer 是签发 token 时写入的 iss，配置后解析时要求 iss 与之一致
+	issuer string
+	// audience 是可接受的 aud 列表，签发时写入 aud，解析时要求 aud 至少包含其中一个
+	audience []string
+	// leeway 是校验 exp、nbf、iat 时允许的时钟偏差
+	leeway time.Duration
 	// expiration 是签发的 token 过期时间
 	expiration time.Duration
+	// maxRefresh 是从最初签发时间起允许刷新 token 的最长时间
+	maxRefresh time.Duration
 	// skipPaths 需要跳过认证的路径列表
 	skipPaths []string
+	// signingMethod 是签发和解析 token 使用的签名算法
+	signingMethod jwt.SigningMethod
+	// publicKey 是 PEM 编码的公钥，用于验证非对称算法签发的 token
+	publicKey string
+	// keyring 用于密钥轮换，配置后签发使用其 active 密钥，验证按 kid 选择密钥
+	keyring *Keyring
+	// remoteKeys 从远程 JWKS 地址加载验证密钥，用于只验证 token 的服务
+	remoteKeys *RemoteKeySet
+	// revocationStore 用于检查 token 是否已被吊销
+	revocationStore RevocationStore
+
+	// signKey 和 verifyKey 是根据 signingMethod 解析出的签名密钥和验证密钥
+	signKey   any
+	verifyKey any
+	// keyErr 记录解析密钥时产生的错误
+	keyErr error
 }
 
 // Option 用于配置 token 包的选项
 type Option func(*Config)
 
-var (
-	config = Config{
-		key:         "",
-		identityKey: "",
-		expiration:  2 * time.Hour,
-		skipPaths:   []string{}, // 默认不跳过任何路径
+// defaultConfig 返回 token 包的默认配置.
+func defaultConfig() Config {
+	return Config{
+		key:           "",
+		identityKey:   "",
+		expiration:    2 * time.Hour,
+		maxRefresh:    2 * time.Hour,
+		skipPaths:     []string{}, // 默认不跳过任何路径
+		signingMethod: jwt.SigningMethodHS256,
 	}
-	once sync.Once // 确保配置只被初始化一次
-)
+}
 
 // 预定义错误
 var (
@@ -60,6 +90,10 @@ var (
 	ErrMalformedAuthHeader = errors.New("malformed authorization header")
 	ErrInvalidTokenClaims  = errors.New("invalid token claims")
 	ErrPathSkipped         = errors.New("path is skipped for authentication") // 新增：路径跳过认证
+
+	ErrUnsupportedSigningMethod = errors.New("unsupported signing method")
+	ErrRefreshExpired           = errors.New("token is beyond the max refresh window")
+	ErrMissingIssuedAt          = errors.New("missing iat claim in token")
 )
 
 // WithKey 设置签名密钥
@@ -78,6 +112,86 @@ func WithIdentityKey(identityKey string) Option {
 	}
 }
 
+// WithSubjectIdentity 使用标准的 sub claim 保存和解析用户身份.
+// 启用后 Sign 将身份写入 sub，解析时从 sub 读取身份，identityKey 不再使用.
+func WithSubjectIdentity() Option {
+	return func(c *Config) {
+		c.useSubject = true
+	}
+}
+
+// WithIssuer 设置 token 的签发者.
+// Sign 会在 token 中写入 iss，解析时要求 token 的 iss 与之一致.
+func WithIssuer(issuer string) Option {
+	return func(c *Config) {
+		c.issuer = issuer
+	}
+}
+
+// WithAudience 设置可接受的 token 受众.
+// Sign 会在 token 中写入 aud，解析时要求 token 的 aud 至少包含其中一个.
+func WithAudience(audience ...string) Option {
+	return func(c *Config) {
+		c.audience = append(c.audience, audience...)
+	}
+}
+
+// WithLeeway 设置校验 exp、nbf、iat 时允许的时钟偏差.
+func WithLeeway(leeway time.Duration) Option {
+	return func(c *Config) {
+		if leeway >= 0 {
+			c.leeway = leeway
+		}
+	}
+}
+
+// WithSigningMethod 设置签名算法，例如 HS256、HS512、RS256、ES256、EdDSA.
+// 使用非对称算法时，Init 传入的 key 应为 PEM 编码的私钥.
+func WithSigningMethod(alg string) Option {
+	return func(c *Config) {
+		c.signingMethod = jwt.GetSigningMethod(alg)
+	}
+}
+
+// WithPublicKey 设置 PEM 编码的公钥，用于验证非对称算法签发的 token.
+// 只负责验证 token 的服务可以只配置公钥而不持有私钥.
+func WithPublicKey(publicKey string) Option {
+	return func(c *Config) {
+		c.publicKey = publicKey
+	}
+}
+
+// WithKeyring 设置用于密钥轮换的 keyring.
+// 配置后 Sign 使用 keyring 中的 active 密钥签发 token 并写入 kid 头部，
+// 解析时根据 kid 选择验证密钥；不带 kid 的 token 仍使用 Init 配置的密钥验证.
+func WithKeyring(keyring *Keyring) Option {
+	return func(c *Config) {
+		c.keyring = keyring
+	}
+}
+
+// WithJWKSURL 设置远程 JWKS 地址，解析 token 时根据 kid 从该地址加载验证密钥.
+// 密钥会被缓存，遇到未知 kid 时自动刷新.
+func WithJWKSURL(url string, opts ...RemoteKeySetOption) Option {
+	return func(c *Config) {
+		c.remoteKeys = NewRemoteKeySet(url, opts...)
+	}
+}
+
+// WithRemoteKeySet 设置用于验证 token 的 RemoteKeySet.
+func WithRemoteKeySet(keySet *RemoteKeySet) Option {
+	return func(c *Config) {
+		c.remoteKeys = keySet
+	}
+}
+
+// WithRevocationStore 设置 token 吊销存储，解析 token 时会检查其是否已被吊销.
+func WithRevocationStore(store RevocationStore) Option {
+	return func(c *Config) {
+		c.revocationStore = store
+	}
+}
+
 // WithExpiration 设置过期时间
 func WithExpiration(expiration time.Duration) Option {
 	return func(c *Config) {
@@ -87,6 +201,16 @@ func WithExpiration(expiration time.Duration) Option {
 	}
 }
 
+// WithMaxRefresh 设置 token 的最长刷新时间.
+// 从 token 最初签发（iat）开始，在 maxRefresh 时间内都可以调用 Refresh 换取新 token.
+func WithMaxRefresh(maxRefresh time.Duration) Option {
+	return func(c *Config) {
+		if maxRefresh >= 0 {
+			c.maxRefresh = maxRefresh
+		}
+	}
+}
+
 // WithSkipPaths 设置需要跳过认证的路径列表
 // 支持精确匹配和通配符匹配
 func WithSkipPaths(paths ...string) Option {
@@ -126,34 +250,106 @@ func WithSkipPathsPattern(patterns ...string) Option {
 	}
 }
 
-// Init 设置包级别的配置 config, config 会用于本包后面的 token 签发和解析.
-func Init(key string, opts ...Option) {
-	once.Do(func() {
-		if key != "" {
-			config.key = key // 设置密钥
+// loadKeys 根据签名算法解析签名密钥和验证密钥.
+func (c *Config) loadKeys() {
+	c.signKey, c.verifyKey, c.keyErr = nil, nil, nil
+
+	if c.signingMethod == nil {
+		c.keyErr = ErrUnsupportedSigningMethod
+		return
+	}
+
+	if c.key != "" {
+		signKey, err := parseSigningKey(c.signingMethod, c.key)
+		if err != nil {
+			c.keyErr = err
+			return
 		}
+		c.signKey = signKey
+		c.verifyKey, c.keyErr = publicKeyOf(signKey)
+	}
 
-		// 应用所有配置选项
-		for _, opt := range opts {
-			opt(&config)
+	// 显式配置的公钥优先用于验证
+	if c.publicKey != "" {
+		c.verifyKey, c.keyErr = parseVerifyKey(c.signingMethod, c.publicKey)
+	}
+}
+
+// signingKey 返回签发 token 使用的密钥.
+func (c *Config) signingKey() (any, error) {
+	if c.keyErr != nil {
+		return nil, c.keyErr
+	}
+	if c.signKey == nil {
+		return nil, jwt.ErrInvalidKey
+	}
+	return c.signKey, nil
+}
+
+// signer 返回签发 token 使用的签名算法、密钥以及 kid.
+func (c *Config) signer() (jwt.SigningMethod, any, string, error) {
+	if c.keyring != nil {
+		key, err := c.keyring.Active()
+		if err != nil {
+			return nil, nil, "", err
 		}
-	})
+		return key.Method, key.signKey, key.ID, nil
+	}
+
+	signKey, err := c.signingKey()
+	if err != nil {
+		return nil, nil, "", err
+	}
+
+	return c.signingMethod, signKey, "", nil
 }
 
-// Reset 重置配置（主要用于测试）
-func Reset() {
-	once = sync.Once{}
-	config = Config{
-		key:         "Rtg8BPKNEf2mB4mgvKONGPZZQSaJWNLijxR42qRgq0iBb5",
-		identityKey: "identityKey",
-		expiration:  2 * time.Hour,
-		skipPaths:   []string{},
+// keyFunc 返回使用该配置验证 token 的 jwt.Keyfunc.
+// token 带有 kid 头部时依次从 keyring 和远程 JWKS 中按 kid 选择验证密钥，否则使用静态配置的密钥.
+// 从远程 JWKS 加载密钥时使用 ctx，请求取消后不再等待 JWKS 端点.
+func (c *Config) keyFunc(ctx context.Context) jwt.Keyfunc {
+	return func(token *jwt.Token) (interface{}, error) {
+		if kid, ok := token.Header["kid"].(string); ok && (c.keyring != nil || c.remoteKeys != nil) {
+			if c.keyring != nil {
+				if key, found := c.keyring.Lookup(kid); found {
+					return keyFuncFor(key.Method, key.verifyKey)(token)
+				}
+			}
+
+			if c.remoteKeys == nil {
+				return nil, ErrUnknownKeyID
+			}
+
+			key, err := c.remoteKeys.Lookup(ctx, kid)
+			if err != nil {
+				return nil, err
+			}
+			return keyFuncFor(key.Method, key.verifyKey)(token)
+		}
+
+		verifyKey, err := c.verificationKey()
+		if err != nil {
+			return nil, err
+		}
+
+		return keyFuncFor(c.signingMethod, verifyKey)(token)
+	}
+}
+
+// verificationKey 返回验证 token 使用的密钥.
+func (c *Config) verificationKey() (any, error) {
+	if c.keyErr != nil {
+		return nil, c.keyErr
+	}
+	if c.verifyKey == nil {
+		return nil, jwt.ErrInvalidKey
 	}
+	return c.verifyKey, nil
 }
 
 // shouldSkipPath 检查路径是否应该跳过认证
-func shouldSkipPath(requestPath string) bool {
-	for _, skipPath := range config.skipPaths {
+func (m *Manager) shouldSkipPath(requestPath string) bool {
+	for _, skipPath := range m.config.skipPaths {
 		if matchPath(requestPath, skipPath) {
 			return true
 		}
@@ -224,49 +420,87 @@ func matchWildcard(str, pattern string) bool {
 }
 
 // ParseIdentity 使用指定的密钥 key 解析 token，解析成功返回 token 上下文，否则报错.
-func ParseIdentity(tokenString string, key string) (string, error) {
+// key 的格式由配置的签名算法决定：HMAC 算法为共享密钥，非对称算法为 PEM 编码的公钥或私钥.
+func (m *Manager) ParseIdentity(tokenString string, key string) (string, error) {
 	if tokenString == "" {
 		return "", ErrEmptyToken
 	}
 
-	if key == "" {
-		return "", jwt.ErrInvalidKey
+	verifyKey, err := parseVerifyKey(m.config.signingMethod, key)
+	if err != nil {
+		return "", err
 	}
 
-	// 解析 token
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		// 确保 token 加密算法符合预期的加密算法
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		return []byte(key), nil
-	})
+	claims, err := m.parseClaims(context.Background(), tokenString, keyFuncFor(m.config.signingMethod, verifyKey))
 	if err != nil {
 		return "", err
 	}
 
+	return m.extractIdentity(claims)
+}
+
+// parseIdentity 使用 Manager 配置的验证密钥解析 token 中的身份信息.
+func (m *Manager) parseIdentity(ctx context.Context, tokenString string) (string, error) {
+	if tokenString == "" {
+		return "", ErrEmptyToken
+	}
+
+	claims, err := m.parseClaims(ctx, tokenString, m.config.keyFunc(ctx))
+	if err != nil {
+		return "", err
+	}
+
+	return m.extractIdentity(claims)
+}
+
+// parseClaims 使用给定的 keyFunc 解析 token，检查 token 是否已被吊销，并返回其中的 claims.
+func (m *Manager) parseClaims(ctx context.Context, tokenString string, keyFunc jwt.Keyfunc) (jwt.MapClaims, error) {
+	// 解析 token 并验证签名，注册声明由 validateClaims 统一校验
+	parser := jwt.NewParser(jwt.WithoutClaimsValidation())
+	token, err := parser.Parse(tokenString, keyFunc)
+	if err != nil {
+		return nil, err
+	}
+
 	// 验证 token 有效性
 	if !token.Valid {
-		return "", jwt.ErrSignatureInvalid
+		return nil, jwt.ErrSignatureInvalid
 	}
 
 	claims, ok := token.Claims.(jwt.MapClaims)
 	if !ok {
-		return "", ErrInvalidTokenClaims
+		return nil, ErrInvalidTokenClaims
 	}
 
-	return extractIdentity(claims)
+	if err := m.validateClaims(claims, true); err != nil {
+		return nil, err
+	}
+
+	if err := m.checkRevocation(ctx, claims); err != nil {
+		return nil, err
+	}
+
+	return claims, nil
 }
 
 // extractIdentity 从 claims 中提取身份信息
-func extractIdentity(claims jwt.MapClaims) (string, error) {
+func (m *Manager) extractIdentity(claims jwt.MapClaims) (string, error) {
+	// 使用标准的 sub claim 作为身份
+	if m.config.useSubject {
+		subject, _ := claims["sub"].(string)
+		if subject == "" {
+			return "", ErrInvalidSubject
+		}
+		return subject, nil
+	}
+
 	// 如果没有配置身份键，返回空字符串（表示不需要身份验证）
-	if config.identityKey == "" {
+	if m.config.identityKey == "" {
 		return "", nil
 	}
 
 	// 检查身份键是否存在
-	value, exists := claims[config.identityKey]
+	value, exists := claims[m.config.identityKey]
 	if !exists {
 		return "", ErrMissingIdentityKey
 	}
@@ -286,40 +520,62 @@ func extractIdentity(claims jwt.MapClaims) (string, error) {
 }
 
 // ParseRequest 从请求头中获取令牌，并将其传递递给 Parse 函数以解析令牌.
-func ParseRequest(ctx context.Context) (string, error) {
+func (m *Manager) ParseRequest(ctx context.Context) (string, error) {
+	identity, _, err := m.ParseRequestWithClaims(ctx)
+	return identity, err
+}
+
+// ParseRequestWithClaims 与 ParseRequest 相同，但同时返回 token 中的所有 claims.
+// 路径被跳过认证时返回空身份和 nil claims.
+func (m *Manager) ParseRequestWithClaims(ctx context.Context) (string, jwt.MapClaims, error) {
 	// 检查是否应该跳过认证
-	if shouldSkipRequestPath(ctx) {
-		return "", nil // 返回特殊错误表示路径被跳过
+	if m.shouldSkipRequestPath(ctx) {
+		return "", nil, nil // 返回特殊错误表示路径被跳过
 	}
 
 	token, err := extractTokenFromRequest(ctx)
 	if err != nil {
-		return "", err
+		return "", nil, err
+	}
+	if token == "" {
+		return "", nil, ErrEmptyToken
 	}
 
-	return ParseIdentity(token, config.key)
+	claims, err := m.parseClaims(ctx, token, m.config.keyFunc(ctx))
+	if err != nil {
+		return "", nil, err
+	}
+
+	identity, err := m.extractIdentity(claims)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return identity, claims, nil
 }
 
 // shouldSkipRequestPath 检查请求路径是否应该跳过认证
-func shouldSkipRequestPath(ctx context.Context) bool {
+func (m *Manager) shouldSkipRequestPath(ctx context.Context) bool {
 	switch typed := ctx.(type) {
 	case *gin.Context:
-		return shouldSkipPath(typed.Request.URL.Path)
+		return m.shouldSkipPath(typed.Request.URL.Path)
 	default:
-		// 对于gRPC，可以从metadata中获取method信息
-		// 这里简化处理，如果需要可以扩展
+		// 对于 gRPC，使用完整的方法名（例如 /pkg.Service/Method）匹配跳过路径
+		if method, ok := grpc.Method(ctx); ok {
+			return m.shouldSkipPath(method)
+		}
 		return false
 	}
 }
 
 // ParseRequestIgnoreSkip 强制解析请求，忽略跳过路径设置
-func ParseRequestIgnoreSkip(ctx context.Context) (string, error) {
+func (m *Manager) ParseRequestIgnoreSkip(ctx context.Context) (string, error) {
 	token, err := extractTokenFromRequest(ctx)
 	if err != nil {
 		return "", err
 	}
 
-	return ParseIdentity(token, config.key)
+	return m.parseIdentity(ctx, token)
 }
 
 // extractTokenFromRequest 从不同类型的请求上下文中提取 token
@@ -367,31 +623,39 @@ func parseAuthorizationHeader(header string) (string, error) {
 }
 
 // Sign 使用 jwtSecret 签发 token，token 的 claims 中会存放传入的 subject.
-func Sign(identityValue string) (string, time.Time, error) {
-	if config.key == "" {
-		return "", time.Time{}, jwt.ErrInvalidKey
+func (m *Manager) Sign(identityValue string) (string, time.Time, error) {
+	method, signKey, kid, err := m.config.signer()
+	if err != nil {
+		return "", time.Time{}, err
 	}
 
 	now := time.Now()
-	expireAt := now.Add(config.expiration)
+	expireAt := now.Add(m.config.expiration)
 
 	// 构建基础 claims
 	claims := jwt.MapClaims{
-		"nbf": now.Unix(),      // token 生效时间
-		"iat": now.Unix(),      // token 签发时间
-		"exp": expireAt.Unix(), // token 过期时间
+		"jti": uuid.New().String(), // token 唯一标识，用于吊销
+		"nbf": now.Unix(),          // token 生效时间
+		"iat": now.Unix(),          // token 签发时间
+		"exp": expireAt.Unix(),     // token 过期时间
 	}
 
-	// 只有在配置了身份键且传入了身份值时，才添加身份信息
-	if config.identityKey != "" && identityValue != "" {
-		claims[config.identityKey] = identityValue
+	// 只有在配置了身份键（或使用 sub）且传入了身份值时，才添加身份信息
+	if m.config.useSubject && identityValue != "" {
+		claims["sub"] = identityValue
+	} else if m.config.identityKey != "" && identityValue != "" {
+		claims[m.config.identityKey] = identityValue
 	}
+	m.stampRegisteredClaims(claims)
 
-	// 创建 token
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	// 创建 token，使用 keyring 时在头部写入 kid
+	token := jwt.NewWithClaims(method, claims)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
 
 	// 签发 token
-	tokenString, err := token.SignedString([]byte(config.key))
+	tokenString, err := token.SignedString(signKey)
 	if err != nil {
 		return "", time.Time{}, fmt.Errorf("failed to sign token: %w", err)
 	}
@@ -400,13 +664,14 @@ func Sign(identityValue string) (string, time.Time, error) {
 }
 
 // SignWithClaims 使用自定义 claims 签发 token
-func SignWithClaims(customClaims jwt.MapClaims) (string, time.Time, error) {
-	if config.key == "" {
-		return "", time.Time{}, jwt.ErrInvalidKey
+func (m *Manager) SignWithClaims(customClaims jwt.MapClaims) (string, time.Time, error) {
+	method, signKey, kid, err := m.config.signer()
+	if err != nil {
+		return "", time.Time{}, err
 	}
 
 	now := time.Now()
-	expireAt := now.Add(config.expiration)
+	expireAt := now.Add(m.config.expiration)
 
 	// 合并自定义 claims 和必要的时间字段
 	claims := make(jwt.MapClaims)
@@ -414,7 +679,10 @@ func SignWithClaims(customClaims jwt.MapClaims) (string, time.Time, error) {
 		claims[k] = v
 	}
 
-	// 确保必要的时间字段存在
+	// 确保 token 唯一标识和必要的时间字段存在
+	if _, exists := claims["jti"]; !exists {
+		claims["jti"] = uuid.New().String()
+	}
 	if _, exists := claims["nbf"]; !exists {
 		claims["nbf"] = now.Unix()
 	}
@@ -424,12 +692,16 @@ func SignWithClaims(customClaims jwt.MapClaims) (string, time.Time, error) {
 	if _, exists := claims["exp"]; !exists {
 		claims["exp"] = expireAt.Unix()
 	}
+	m.stampRegisteredClaims(claims)
 
-	// 创建 token
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	// 创建 token，使用 keyring 时在头部写入 kid
+	token := jwt.NewWithClaims(method, claims)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
 
 	// 签发 token
-	tokenString, err := token.SignedString([]byte(config.key))
+	tokenString, err := token.SignedString(signKey)
 	if err != nil {
 		return "", time.Time{}, fmt.Errorf("failed to sign token: %w", err)
 	}
@@ -437,118 +709,113 @@ func SignWithClaims(customClaims jwt.MapClaims) (string, time.Time, error) {
 	return tokenString, expireAt, nil
 }
 
-// Parse 验证 token 字符串的有效性（不解析身份信息）
-func Parse(tokenString string) error {
-	if tokenString == "" {
-		return ErrEmptyToken
+// Refresh 使用旧 token 换取一个新 token.
+// 旧 token 的签名必须有效，但允许已经过期；只要距离最初签发时间（orig_iat，首次签发时为 iat）
+// 不超过 maxRefresh，就会签发一个保留原有自定义 claims 的新 token.
+func (m *Manager) Refresh(oldToken string) (string, time.Time, error) {
+	if oldToken == "" {
+		return "", time.Time{}, ErrEmptyToken
 	}
 
-	if config.key == "" {
-		return jwt.ErrInvalidKey
-	}
-
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		return []byte(config.key), nil
-	})
+	// 验证签名但跳过 exp/nbf 校验，允许刷新已过期的 token
+	parser := jwt.NewParser(jwt.WithoutClaimsValidation())
+	token, err := parser.Parse(oldToken, m.config.keyFunc(context.Background()))
 	if err != nil {
-		return err
+		return "", time.Time{}, err
 	}
 
-	if !token.Valid {
-		return jwt.ErrSignatureInvalid
+	oldClaims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return "", time.Time{}, ErrInvalidTokenClaims
 	}
 
-	return nil
-}
-
-// GetClaims 获取 token 中的所有 claims
-func GetClaims(tokenString string) (jwt.MapClaims, error) {
-	if tokenString == "" {
-		return nil, ErrEmptyToken
+	if err := m.validateClaims(oldClaims, false); err != nil {
+		return "", time.Time{}, err
 	}
 
-	if config.key == "" {
-		return nil, jwt.ErrInvalidKey
+	// 已被吊销的 token 不允许刷新
+	if err := m.checkRevocation(context.Background(), oldClaims); err != nil {
+		return "", time.Time{}, err
 	}
 
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		return []byte(config.key), nil
-	})
+	origIat, err := originalIssuedAt(oldClaims)
 	if err != nil {
-		return nil, err
+		return "", time.Time{}, err
 	}
 
-	if !token.Valid {
-		return nil, jwt.ErrSignatureInvalid
+	if time.Now().After(origIat.Add(m.config.maxRefresh)) {
+		return "", time.Time{}, ErrRefreshExpired
 	}
 
-	claims, ok := token.Claims.(jwt.MapClaims)
-	if !ok {
-		return nil, ErrInvalidTokenClaims
+	claims := make(jwt.MapClaims, len(oldClaims))
+	for k, v := range oldClaims {
+		switch k {
+		case "jti", "nbf", "iat", "exp":
+			// 由 SignWithClaims 重新生成
+		default:
+			claims[k] = v
+		}
 	}
+	claims["orig_iat"] = origIat.Unix()
 
-	return claims, nil
+	return m.SignWithClaims(claims)
 }
 
-// GetConfig 获取当前配置（用于调试和测试）
-func GetConfig() Config {
-	return config
-}
+// originalIssuedAt 返回 token 最初的签发时间，刷新过的 token 使用 orig_iat，否则使用 iat.
+func originalIssuedAt(claims jwt.MapClaims) (time.Time, error) {
+	for _, name := range []string{"orig_iat", "iat"} {
+		if t, ok := timeClaim(claims, name); ok {
+			return t, nil
+		}
+	}
 
-// IsIdentityRequired 检查是否需要身份验证
-func IsIdentityRequired() bool {
-	return config.identityKey != ""
+	return time.Time{}, ErrMissingIssuedAt
 }
 
-// GetExpiration 获取当前配置的过期时间
-func GetExpiration() time.Duration {
-	return config.expiration
+// timeClaim 读取以 Unix 时间戳表示的时间类 claim.
+func timeClaim(claims jwt.MapClaims, name string) (time.Time, bool) {
+	switch v := claims[name].(type) {
+	case float64:
+		return time.Unix(int64(v), 0), true
+	case int64:
+		return time.Unix(v, 0), true
+	case json.Number:
+		n, err := v.Int64()
+		if err != nil {
+			return time.Time{}, false
+		}
+		return time.Unix(n, 0), true
+	default:
+		return time.Time{}, false
+	}
 }
 
-// GetSkipPaths 获取跳过认证的路径列表
-func GetSkipPaths() []string {
-	return append([]string{}, config.skipPaths...) // 返回副本
+// Parse 验证 token 字符串的有效性（不解析身份信息）
+func (m *Manager) Parse(tokenString string) error {
+	_, err := m.GetClaims(tokenString)
+	return err
 }
 
-// IsPathSkipped 检查指定路径是否被跳过认证
-func IsPathSkipped(path string) bool {
-	return shouldSkipPath(path)
+// GetClaims 获取 token 中的所有 claims
+func (m *Manager) GetClaims(tokenString string) (jwt.MapClaims, error) {
+	if tokenString == "" {
+		return nil, ErrEmptyToken
+	}
+
+	return m.parseClaims(context.Background(), tokenString, m.config.keyFunc(context.Background()))
 }
 
 // ParseWithKey 使用自定义密钥解析 token
-func ParseWithKey(tokenString, key string) (jwt.MapClaims, error) {
+// key 的格式由配置的签名算法决定：HMAC 算法为共享密钥，非对称算法为 PEM 编码的公钥或私钥.
+func (m *Manager) ParseWithKey(tokenString, key string) (jwt.MapClaims, error) {
 	if tokenString == "" {
 		return nil, ErrEmptyToken
 	}
 
-	if key == "" {
-		return nil, jwt.ErrInvalidKey
-	}
-
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		return []byte(key), nil
-	})
+	verifyKey, err := parseVerifyKey(m.config.signingMethod, key)
 	if err != nil {
 		return nil, err
 	}
 
-	if !token.Valid {
-		return nil, jwt.ErrSignatureInvalid
-	}
-
-	claims, ok := token.Claims.(jwt.MapClaims)
-	if !ok {
-		return nil, ErrInvalidTokenClaims
-	}
-
-	return claims, nil
+	return m.parseClaims(context.Background(), tokenString, keyFuncFor(m.config.signingMethod, verifyKey))
 }
@@ -0,0 +1,124 @@
+/**FileHeader
+ * @Author: Liangkang Zhang
+ * @Date: 2026/2/10 20:14:37
+ * @LastEditors: Liangkang Zhang
+ * @LastEditTime: 2026/2/10 20:14:37
+ * @Description:
+ * @Copyright: Copyright (©)}) 2026 Liangkang Zhang<lkzhang98@gmail.com>. All rights reserved. Use of this source code is governed by a MIT style license that can be found in the LICENSE file.. All rights reserved.
+ * @Email: lkzhang98@gmail.com
+ * @Repository: https://github.com/geminik12/autostack
+ */
+package token
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/geminik12/autostack/contextx"
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// TypedClaims 是强类型 claims 的约束，自定义 claims 结构体通过嵌入 jwt.RegisteredClaims 满足该约束.
+//
+//	type UserClaims struct {
+//		jwt.RegisteredClaims
+//		Roles    []string `json:"roles"`
+//		TenantID string   `json:"tenantID"`
+//	}
+//
+// 签发和解析时 claims 会按照结构体的 json tag 与 token 中的字段对应.
+type TypedClaims interface {
+	jwt.Claims
+}
+
+// SignTyped 使用默认 Manager 签发携带强类型 claims 的 token，详见 SignTypedWithManager.
+func SignTyped[C TypedClaims](claims C) (string, time.Time, error) {
+	return SignTypedWithManager(Default(), claims)
+}
+
+// SignTypedWithManager 使用指定的 Manager 签发携带强类型 claims 的 token.
+// 未设置的 jti、nbf、iat、exp 以及配置的 iss、aud 会和 SignWithClaims 一样自动补全.
+func SignTypedWithManager[C TypedClaims](m *Manager, claims C) (string, time.Time, error) {
+	mapClaims, err := toMapClaims(claims)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	return m.SignWithClaims(mapClaims)
+}
+
+// ParseTyped 使用默认 Manager 解析 token 并返回强类型 claims，详见 ParseTypedWithManager.
+func ParseTyped[C any, PC interface {
+	*C
+	TypedClaims
+}](tokenString string) (*C, error) {
+	return ParseTypedWithManager[C, PC](Default(), tokenString)
+}
+
+// ParseTypedWithManager 使用指定的 Manager 解析 token 并返回强类型 claims.
+// token 的签名、注册声明和吊销状态的校验与 GetClaims 相同.
+func ParseTypedWithManager[C any, PC interface {
+	*C
+	TypedClaims
+}](m *Manager, tokenString string) (*C, error) {
+	mapClaims, err := m.GetClaims(tokenString)
+	if err != nil {
+		return nil, err
+	}
+
+	return fromMapClaims[C, PC](mapClaims)
+}
+
+// ClaimsFromContext 从上下文中提取强类型 claims.
+// 上下文中的 claims 由认证中间件通过 contextx.WithClaims 存放，可以是 jwt.MapClaims 或 *C.
+func ClaimsFromContext[C any, PC interface {
+	*C
+	TypedClaims
+}](ctx context.Context) (*C, bool) {
+	switch v := contextx.Claims(ctx).(type) {
+	case *C:
+		return v, true
+	case jwt.MapClaims:
+		claims, err := fromMapClaims[C, PC](v)
+		if err != nil {
+			return nil, false
+		}
+		return claims, true
+	default:
+		return nil, false
+	}
+}
+
+// toMapClaims 将强类型 claims 转换为 jwt.MapClaims.
+func toMapClaims(claims any) (jwt.MapClaims, error) {
+	data, err := json.Marshal(claims)
+	if err != nil {
+		return nil, err
+	}
+
+	mapClaims := make(jwt.MapClaims)
+	if err := json.Unmarshal(data, &mapClaims); err != nil {
+		return nil, err
+	}
+
+	return mapClaims, nil
+}
+
+// fromMapClaims 将 jwt.MapClaims 转换为强类型 claims.
+func fromMapClaims[C any, PC interface {
+	*C
+	TypedClaims
+}](mapClaims jwt.MapClaims) (*C, error) {
+	data, err := json.Marshal(mapClaims)
+	if err != nil {
+		return nil, err
+	}
+
+	claims := new(C)
+	if err := json.Unmarshal(data, PC(claims)); err != nil {
+		return nil, ErrInvalidTokenClaims
+	}
+
+	return claims, nil
+}